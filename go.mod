@@ -2,7 +2,6 @@ module github.com/mofcloud/mof-common
 
 go 1.19
 
-require (
-	github.com/pkg/errors v0.9.1
-	golang.org/x/text v0.5.0
-)
+require golang.org/x/text v0.5.0
+
+require gopkg.in/yaml.v3 v3.0.1
@@ -0,0 +1,244 @@
+/*
+ * Copyright (c) 2022 The Mof Authors
+ */
+
+package pio
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// ****** Canonical JSON ******
+//
+// Canonical form follows RFC 8785 (JSON Canonicalization Scheme):
+// - no insignificant whitespace
+// - object keys are sorted by UTF-16 code units
+// - numbers are IEEE 754 doubles, formatted like ECMAScript Number.toString, 4.50 => 4.5, 1e21 => 1e+21
+// - strings only escape ", \ and control characters, HTML characters are kept as they are
+
+// CanonicalJSON marshal v with encoding/json and convert it to canonical form
+func CanonicalJSON(v interface{}) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return CanonicalizeJSON(b)
+}
+
+// CanonicalizeJSON convert JSON document to canonical form
+func CanonicalizeJSON(raw []byte) ([]byte, error) {
+	v, err := decodeSingleJSON(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	if err := writeCanonical(buf, v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// decodeSingleJSON decode raw which must hold exactly one JSON value, numbers are kept as json.Number
+func decodeSingleJSON(raw []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+
+	var res interface{}
+	if err := dec.Decode(&res); err != nil {
+		return nil, err
+	}
+
+	if err := expectJSONEnd(dec); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// expectJSONEnd checks nothing but spaces is left after top-level value
+func expectJSONEnd(dec *json.Decoder) error {
+	if _, err := dec.Token(); err != io.EOF {
+		return errors.New("invalid JSON: unexpected data after top-level value")
+	}
+
+	return nil
+}
+
+// StableHash returns hex encoded SHA-256 of canonical JSON of v
+//
+// Values which are equal as JSON have the same hash regardless of key order and number format.
+func StableHash(v interface{}) (string, error) {
+	b, err := CanonicalJSON(v)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func writeCanonical(buf *bytes.Buffer, v interface{}) error {
+	switch t := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(t))
+	case json.Number:
+		f, err := strconv.ParseFloat(string(t), 64)
+		if err != nil {
+			return errors.New(fmt.Sprintf("invalid JSON number: %s, %v", t, err))
+		}
+		buf.WriteString(formatCanonicalNumber(f))
+	case string:
+		writeCanonicalString(buf, t)
+	case []interface{}:
+		buf.WriteByte('[')
+		for i := range t {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonical(buf, t[i]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case map[string]interface{}:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return lessUTF16(keys[i], keys[j])
+		})
+
+		buf.WriteByte('{')
+		for i := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeCanonicalString(buf, keys[i])
+			buf.WriteByte(':')
+			if err := writeCanonical(buf, t[keys[i]]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return errors.New(fmt.Sprintf("unsupported JSON value: %T", v))
+	}
+
+	return nil
+}
+
+// formatCanonicalNumber format f like ECMAScript Number.prototype.toString
+func formatCanonicalNumber(f float64) string {
+	if f == 0 {
+		// -0 as well
+		return "0"
+	}
+
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "null"
+	}
+
+	sign := ""
+	if f < 0 {
+		sign = "-"
+		f = -f
+	}
+
+	// shortest digits which round trip, like 1.2345e+06
+	str := strconv.FormatFloat(f, 'e', -1, 64)
+	mantissa, exp := str, 0
+	if i := strings.IndexByte(str, 'e'); i >= 0 {
+		mantissa = str[:i]
+		exp, _ = strconv.Atoi(str[i+1:])
+	}
+
+	digits := strings.Replace(mantissa, ".", "", 1)
+	k := len(digits)
+	// value is 0.digits * 10^n
+	n := exp + 1
+
+	var res string
+	switch {
+	case k <= n && n <= 21:
+		res = digits + strings.Repeat("0", n-k)
+	case 0 < n && n <= 21:
+		res = digits[:n] + "." + digits[n:]
+	case -6 < n && n <= 0:
+		res = "0." + strings.Repeat("0", -n) + digits
+	default:
+		res = digits[:1]
+		if k > 1 {
+			res += "." + digits[1:]
+		}
+
+		if n-1 >= 0 {
+			res += "e+" + strconv.Itoa(n-1)
+		} else {
+			res += "e" + strconv.Itoa(n-1)
+		}
+	}
+
+	return sign + res
+}
+
+func writeCanonicalString(buf *bytes.Buffer, str string) {
+	buf.WriteByte('"')
+
+	for _, r := range str {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(buf, `\u%04x`, r)
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+	}
+
+	buf.WriteByte('"')
+}
+
+// lessUTF16 compare strings by UTF-16 code units, which differs from byte order for characters above U+FFFF
+func lessUTF16(a, b string) bool {
+	ua := utf16.Encode([]rune(a))
+	ub := utf16.Encode([]rune(b))
+
+	for i := 0; i < len(ua) && i < len(ub); i++ {
+		if ua[i] != ub[i] {
+			return ua[i] < ub[i]
+		}
+	}
+
+	return len(ua) < len(ub)
+}
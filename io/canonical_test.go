@@ -0,0 +1,99 @@
+package pio
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFormatCanonicalNumber(t *testing.T) {
+	tests := []struct {
+		in     float64
+		wanted string
+	}{
+		{in: 0, wanted: "0"},
+		{in: math.Copysign(0, -1), wanted: "0"},
+		{in: 1, wanted: "1"},
+		{in: -1.5, wanted: "-1.5"},
+		{in: 4.50, wanted: "4.5"},
+		{in: 0.002, wanted: "0.002"},
+		{in: 0.000001, wanted: "0.000001"},
+		{in: 1e-7, wanted: "1e-7"},
+		{in: 1e21, wanted: "1e+21"},
+		{in: 1e23, wanted: "1e+23"},
+		{in: 123456789012345680000, wanted: "123456789012345680000"},
+		{in: 333333333.33333329, wanted: "333333333.3333333"},
+		{in: 5e-324, wanted: "5e-324"},
+		{in: 1.7976931348623157e308, wanted: "1.7976931348623157e+308"},
+		{in: 9007199254740992, wanted: "9007199254740992"},
+	}
+
+	for _, tt := range tests {
+		if got := formatCanonicalNumber(tt.in); got != tt.wanted {
+			t.Errorf("got %q, wanted %q", got, tt.wanted)
+		}
+	}
+}
+
+func TestCanonicalizeJSON(t *testing.T) {
+	tests := []struct {
+		in     string
+		wanted string
+	}{
+		{
+			in:     `{ "b": [1.0, 2e0, "x"], "a": {"d": null, "c": true} }`,
+			wanted: `{"a":{"c":true,"d":null},"b":[1,2,"x"]}`,
+		},
+		{
+			// sorted by UTF-16 code units, from RFC 8785
+			in:     `{"\u20ac":1,"\r":2,"\ufb33":3,"1":4,"\ud83d\ude00":5,"\u0080":6,"\u00f6":7}`,
+			wanted: "{\"\\r\":2,\"1\":4,\"\u0080\":6,\"\u00f6\":7,\"\u20ac\":1,\"\U0001F600\":5,\"\ufb33\":3}",
+		},
+		{
+			in:     `"<a & b>\u0001\u2028"`,
+			wanted: "\"<a & b>\\u0001\u2028\"",
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := CanonicalizeJSON([]byte(tt.in))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(got) != tt.wanted {
+			t.Errorf("got %q, wanted %q", got, tt.wanted)
+		}
+	}
+
+	for _, in := range []string{`{"a":1} {}`, `{"a":1} x`, `{"a":1}]`, `{"a":}`, `1e400`} {
+		if _, err := CanonicalizeJSON([]byte(in)); err == nil {
+			t.Errorf("got nil, wanted error for %q", in)
+		}
+	}
+}
+
+func TestStableHash(t *testing.T) {
+	type record struct {
+		Month string  `json:"month"`
+		Cost  float64 `json:"cost"`
+	}
+
+	a, err := StableHash(record{Month: "2024-01", Cost: 1.50})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := StableHash(map[string]interface{}{"cost": 1.5, "month": "2024-01"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if a != b || len(a) != 64 {
+		t.Errorf("got %q and %q, wanted same hash", a, b)
+	}
+
+	c, _ := StableHash(record{Month: "2024-01", Cost: 1.51})
+	if a == c {
+		t.Errorf("got same hash %q, wanted different", a)
+	}
+}
@@ -0,0 +1,404 @@
+/*
+ * Copyright (c) 2022 The Mof Authors
+ */
+
+package pio
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mofcloud/mof-common/math"
+)
+
+// ****** Flex types ******
+//
+// Billing APIs of cloud providers return numbers in different shapes, like
+// "12.3400000000", 12.34, "1.234E+1", "" or null. Flex types accept all of them
+// when unmarshalled from JSON, empty string and null are zero values.
+
+// FlexFormat how Flex types are marshalled to JSON
+type FlexFormat int
+
+const (
+	// FlexAsNumber marshal as JSON number or bool, like 12.34
+	FlexAsNumber FlexFormat = iota
+	// FlexAsString marshal as JSON string, like "12.34"
+	FlexAsString
+)
+
+// FlexMarshalFormat default format used by MarshalJSON of FlexFloat, FlexInt, FlexDecimal and FlexBool
+//
+// It is read without locking, set it once at init and do not change it afterwards. Use FlexFloatString,
+// FlexIntString, FlexDecimalString or FlexBoolString for values always marshalled as JSON string.
+var FlexMarshalFormat = FlexAsNumber
+
+// maxDecimalExponent largest exponent accepted by scientific notation
+const maxDecimalExponent = 1000
+
+var decimalRegexp = regexp.MustCompile(`^([+-]?)(\d*)(?:\.(\d*))?(?:[eE]([+-]?\d+))?$`)
+
+// FlexFloat float64 accepts JSON number, numeric string, scientific notation, empty string and null
+type FlexFloat float64
+
+func (f FlexFloat) Float64() float64 {
+	return float64(f)
+}
+
+func (f FlexFloat) String() string {
+	return strconv.FormatFloat(float64(f), 'f', -1, 64)
+}
+
+func (f FlexFloat) MarshalJSON() ([]byte, error) {
+	if math.IsNaN(float64(f)) || math.IsInf(float64(f), 0) {
+		return nil, errors.New(fmt.Sprintf("unsupported flex float value: %v", float64(f)))
+	}
+
+	return marshalFlex(f.String(), FlexMarshalFormat), nil
+}
+
+func (f *FlexFloat) UnmarshalJSON(b []byte) error {
+	text, err := flexText(b)
+	if err != nil {
+		return err
+	}
+
+	if text == "" {
+		*f = 0
+		return nil
+	}
+
+	v, err := strconv.ParseFloat(text, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return errors.New(fmt.Sprintf("invalid flex float: %s", b))
+	}
+
+	*f = FlexFloat(v)
+	return nil
+}
+
+// FlexInt int64 accepts JSON number, numeric string, scientific notation, empty string and null
+//
+// Numbers with fraction like 1.5 are rejected, 1.0 and 1e3 are accepted.
+type FlexInt int64
+
+func (i FlexInt) Int64() int64 {
+	return int64(i)
+}
+
+func (i FlexInt) String() string {
+	return strconv.FormatInt(int64(i), 10)
+}
+
+func (i FlexInt) MarshalJSON() ([]byte, error) {
+	return marshalFlex(i.String(), FlexMarshalFormat), nil
+}
+
+func (i *FlexInt) UnmarshalJSON(b []byte) error {
+	text, err := flexText(b)
+	if err != nil {
+		return err
+	}
+
+	if text == "" {
+		*i = 0
+		return nil
+	}
+
+	if v, err := strconv.ParseInt(text, 10, 64); err == nil {
+		*i = FlexInt(v)
+		return nil
+	}
+
+	d, err := ParseFlexDecimal(text)
+	if err != nil {
+		return errors.New(fmt.Sprintf("invalid flex int: %s", b))
+	}
+
+	intPart, frac, _ := strings.Cut(d.value, ".")
+	if strings.Trim(frac, "0") != "" {
+		return errors.New(fmt.Sprintf("invalid flex int: %s, fraction is not allowed", b))
+	}
+
+	v, err := strconv.ParseInt(intPart, 10, 64)
+	if err != nil {
+		return errors.New(fmt.Sprintf("invalid flex int: %s, %v", b, err))
+	}
+
+	*i = FlexInt(v)
+	return nil
+}
+
+// FlexBool bool accepts JSON bool, number, strings like "true", "1", "yes", "on", empty string and null
+type FlexBool bool
+
+func (b FlexBool) Bool() bool {
+	return bool(b)
+}
+
+func (b FlexBool) String() string {
+	return strconv.FormatBool(bool(b))
+}
+
+func (b FlexBool) MarshalJSON() ([]byte, error) {
+	return marshalFlex(b.String(), FlexMarshalFormat), nil
+}
+
+func (b *FlexBool) UnmarshalJSON(raw []byte) error {
+	text, err := flexText(raw)
+	if err != nil {
+		return err
+	}
+
+	switch strings.ToLower(text) {
+	case "", "false", "no", "n", "off":
+		*b = false
+		return nil
+	case "true", "yes", "y", "on":
+		*b = true
+		return nil
+	}
+
+	v, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return errors.New(fmt.Sprintf("invalid flex bool: %s", raw))
+	}
+
+	*b = v != 0
+	return nil
+}
+
+// FlexDecimal decimal number kept as plain decimal string, original precision is preserved
+//
+// "12.3400000000" stays as it is, "1.2340E+2" is expanded to "123.40" without rounding.
+// Zero value is empty, which is marshalled as null.
+type FlexDecimal struct {
+	value string
+}
+
+// ParseFlexDecimal parse decimal or scientific notation, commas are ignored
+func ParseFlexDecimal(str string) (FlexDecimal, error) {
+	str = strings.ReplaceAll(strings.TrimSpace(str), ",", "")
+	if str == "" {
+		return FlexDecimal{}, nil
+	}
+
+	value, err := expandDecimal(str)
+	if err != nil {
+		return FlexDecimal{}, err
+	}
+
+	return FlexDecimal{value: value}, nil
+}
+
+// IsEmpty checks whether decimal came from empty string or null
+func (d FlexDecimal) IsEmpty() bool {
+	return d.value == ""
+}
+
+// String returns plain decimal string, empty if IsEmpty
+func (d FlexDecimal) String() string {
+	return d.value
+}
+
+// Float64 convert to float64, empty decimal is 0
+func (d FlexDecimal) Float64() float64 {
+	v, _ := strconv.ParseFloat(d.value, 64)
+	return v
+}
+
+// Round convert to float64 with precision, see pmath.RoundFloat64FromString
+func (d FlexDecimal) Round(precision int) float64 {
+	if d.IsEmpty() {
+		return 0
+	}
+
+	v, _ := pmath.RoundFloat64FromString(d.value, precision)
+	return v
+}
+
+func (d FlexDecimal) MarshalJSON() ([]byte, error) {
+	if d.IsEmpty() {
+		return []byte("null"), nil
+	}
+
+	return marshalFlex(d.value, FlexMarshalFormat), nil
+}
+
+func (d *FlexDecimal) UnmarshalJSON(b []byte) error {
+	text, err := flexText(b)
+	if err != nil {
+		return err
+	}
+
+	res, err := ParseFlexDecimal(text)
+	if err != nil {
+		return errors.New(fmt.Sprintf("invalid flex decimal: %s", b))
+	}
+
+	*d = res
+	return nil
+}
+
+// FlexFloatString FlexFloat always marshalled as JSON string, like "12.34"
+type FlexFloatString FlexFloat
+
+func (f FlexFloatString) Float64() float64 {
+	return float64(f)
+}
+
+func (f FlexFloatString) String() string {
+	return FlexFloat(f).String()
+}
+
+func (f FlexFloatString) MarshalJSON() ([]byte, error) {
+	if math.IsNaN(float64(f)) || math.IsInf(float64(f), 0) {
+		return nil, errors.New(fmt.Sprintf("unsupported flex float value: %v", float64(f)))
+	}
+
+	return marshalFlex(f.String(), FlexAsString), nil
+}
+
+func (f *FlexFloatString) UnmarshalJSON(b []byte) error {
+	return (*FlexFloat)(f).UnmarshalJSON(b)
+}
+
+// FlexIntString FlexInt always marshalled as JSON string, like "3"
+type FlexIntString FlexInt
+
+func (i FlexIntString) Int64() int64 {
+	return int64(i)
+}
+
+func (i FlexIntString) String() string {
+	return FlexInt(i).String()
+}
+
+func (i FlexIntString) MarshalJSON() ([]byte, error) {
+	return marshalFlex(i.String(), FlexAsString), nil
+}
+
+func (i *FlexIntString) UnmarshalJSON(b []byte) error {
+	return (*FlexInt)(i).UnmarshalJSON(b)
+}
+
+// FlexBoolString FlexBool always marshalled as JSON string, like "true"
+type FlexBoolString FlexBool
+
+func (b FlexBoolString) Bool() bool {
+	return bool(b)
+}
+
+func (b FlexBoolString) String() string {
+	return FlexBool(b).String()
+}
+
+func (b FlexBoolString) MarshalJSON() ([]byte, error) {
+	return marshalFlex(b.String(), FlexAsString), nil
+}
+
+func (b *FlexBoolString) UnmarshalJSON(raw []byte) error {
+	return (*FlexBool)(b).UnmarshalJSON(raw)
+}
+
+// FlexDecimalString FlexDecimal always marshalled as JSON string, like "12.3400000000"
+//
+// Zero value is still marshalled as null.
+type FlexDecimalString struct {
+	FlexDecimal
+}
+
+func (d FlexDecimalString) MarshalJSON() ([]byte, error) {
+	if d.IsEmpty() {
+		return []byte("null"), nil
+	}
+
+	return marshalFlex(d.value, FlexAsString), nil
+}
+
+// flexText returns trimmed text of JSON value, string is unquoted and commas are removed
+//
+// Empty text is returned for null and empty string.
+func flexText(b []byte) (string, error) {
+	b = bytes.TrimSpace(b)
+	if bytes.Equal(b, []byte("null")) {
+		return "", nil
+	}
+
+	if len(b) > 0 && b[0] == '"' {
+		var str string
+		if err := json.Unmarshal(b, &str); err != nil {
+			return "", err
+		}
+
+		return strings.ReplaceAll(strings.TrimSpace(str), ",", ""), nil
+	}
+
+	if len(b) > 0 && (b[0] == '{' || b[0] == '[') {
+		return "", errors.New(fmt.Sprintf("invalid flex value: %s, object and array are not supported", b))
+	}
+
+	return string(b), nil
+}
+
+// marshalFlex write text following format
+func marshalFlex(text string, format FlexFormat) []byte {
+	if format == FlexAsString {
+		return []byte(strconv.Quote(text))
+	}
+
+	return []byte(text)
+}
+
+// expandDecimal convert decimal or scientific notation to plain decimal without changing digits
+//
+// 1.2340E+2 => 123.40, -5e-3 => -0.005, +.5 => 0.5, 007 => 7
+func expandDecimal(str string) (string, error) {
+	m := decimalRegexp.FindStringSubmatch(str)
+	if m == nil || m[2]+m[3] == "" {
+		return "", errors.New(fmt.Sprintf("invalid decimal: %q", str))
+	}
+
+	sign, intPart, fracPart := m[1], m[2], m[3]
+	if sign == "+" {
+		sign = ""
+	}
+
+	exp := 0
+	if m[4] != "" {
+		v, err := strconv.Atoi(m[4])
+		if err != nil || v > maxDecimalExponent || v < -maxDecimalExponent {
+			return "", errors.New(fmt.Sprintf("invalid decimal: %q, exponent out of range", str))
+		}
+		exp = v
+	}
+
+	digits := intPart + fracPart
+	point := len(intPart) + exp
+
+	switch {
+	case point <= 0:
+		intPart, fracPart = "0", strings.Repeat("0", -point)+digits
+	case point >= len(digits):
+		intPart, fracPart = digits+strings.Repeat("0", point-len(digits)), ""
+	default:
+		intPart, fracPart = digits[:point], digits[point:]
+	}
+
+	intPart = strings.TrimLeft(intPart, "0")
+	if intPart == "" {
+		intPart = "0"
+	}
+
+	if fracPart == "" {
+		return sign + intPart, nil
+	}
+
+	return sign + intPart + "." + fracPart, nil
+}
@@ -0,0 +1,132 @@
+package pio
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type billingLine struct {
+	Amount   FlexFloat   `json:"amount"`
+	Usage    FlexDecimal `json:"usage"`
+	Quantity FlexInt     `json:"quantity"`
+	Credit   FlexBool    `json:"credit"`
+}
+
+func TestFlexUnmarshal(t *testing.T) {
+	tests := []struct {
+		in     string
+		wanted billingLine
+		usage  string
+	}{
+		{
+			in:     `{"amount":"12.3400000000","usage":"12.3400000000","quantity":"3","credit":"true"}`,
+			wanted: billingLine{Amount: 12.34, Quantity: 3, Credit: true},
+			usage:  "12.3400000000",
+		},
+		{
+			in:     `{"amount":1.234E+1,"usage":1.2340E+2,"quantity":1e3,"credit":1}`,
+			wanted: billingLine{Amount: 12.34, Quantity: 1000, Credit: true},
+			usage:  "123.40",
+		},
+		{
+			in:     `{"amount":"","usage":null,"quantity":"","credit":""}`,
+			wanted: billingLine{},
+			usage:  "",
+		},
+		{
+			in:     `{"amount":"1,234.5","usage":"-5e-3","quantity":"2.0","credit":"no"}`,
+			wanted: billingLine{Amount: 1234.5, Quantity: 2},
+			usage:  "-0.005",
+		},
+	}
+
+	for _, tt := range tests {
+		got := billingLine{}
+		if err := json.Unmarshal([]byte(tt.in), &got); err != nil {
+			t.Fatalf("%s: %v", tt.in, err)
+		}
+
+		if got.Amount != tt.wanted.Amount || got.Quantity != tt.wanted.Quantity || got.Credit != tt.wanted.Credit {
+			t.Errorf("got %+v, wanted %+v", got, tt.wanted)
+		}
+
+		if got.Usage.String() != tt.usage {
+			t.Errorf("got %q, wanted %q", got.Usage.String(), tt.usage)
+		}
+	}
+
+	for _, in := range []string{`{"amount":"abc"}`, `{"quantity":1.5}`, `{"usage":"1e"}`, `{"credit":"maybe"}`, `{"amount":"NaN"}`} {
+		if err := json.Unmarshal([]byte(in), &billingLine{}); err == nil {
+			t.Errorf("got nil, wanted error for %s", in)
+		}
+	}
+}
+
+func TestFlexMarshal(t *testing.T) {
+	usage, err := ParseFlexDecimal("1.50E+1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	line := billingLine{Amount: 12.5, Usage: usage, Quantity: 3, Credit: true}
+
+	b, err := json.Marshal(line)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, wanted := string(b), `{"amount":12.5,"usage":15.0,"quantity":3,"credit":true}`; got != wanted {
+		t.Errorf("got %q, wanted %q", got, wanted)
+	}
+
+	quoted := struct {
+		Amount   FlexFloatString   `json:"amount"`
+		Usage    FlexDecimalString `json:"usage"`
+		Quantity FlexIntString     `json:"quantity"`
+		Credit   FlexBoolString    `json:"credit"`
+	}{}
+
+	if err := json.Unmarshal(b, &quoted); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err = json.Marshal(quoted)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, wanted := string(b), `{"amount":"12.5","usage":"15.0","quantity":"3","credit":"true"}`; got != wanted {
+		t.Errorf("got %q, wanted %q", got, wanted)
+	}
+
+	if got := usage.Round(0); got != 15 {
+		t.Errorf("got %v, wanted %v", got, 15)
+	}
+}
+
+func TestExpandDecimal(t *testing.T) {
+	tests := map[string]string{
+		"007":        "7",
+		"+.5":        "0.5",
+		"5.":         "5",
+		"1.2340E+2":  "123.40",
+		"1.2340e-2":  "0.012340",
+		"12E3":       "12000",
+		"-0.00":      "-0.00",
+		"0.0000012":  "0.0000012",
+		"123.456e-1": "12.3456",
+	}
+
+	for in, wanted := range tests {
+		got, err := expandDecimal(in)
+		if err != nil || got != wanted {
+			t.Errorf("%s: got %q %v, wanted %q", in, got, err, wanted)
+		}
+	}
+
+	for _, in := range []string{".", "e5", "1e5000", "1.2.3", "--1"} {
+		if _, err := expandDecimal(in); err == nil {
+			t.Errorf("got nil, wanted error for %q", in)
+		}
+	}
+}
@@ -7,72 +7,76 @@ package pio
 import (
 	"encoding/json"
 	"fmt"
+	"os"
 	"strings"
+
+	"github.com/mofcloud/mof-common/output"
 )
 
+// PrintStructPretty print in as indented JSON to stdout with secrets redacted, error is printed to stderr
+//
+// Redaction is always applied, which changes output of structs with secrets: fields tagged with
+// mof:"secret", values of keys whose name contains secret, password, private_key or credential,
+// keys like token, and AWS or Alibaba access key IDs and PEM private keys in any string are
+// printed as ******. See RedactedJSON for details, use output.Write to print without redaction.
 func PrintStructPretty(in interface{}) {
-	bytes, _ := json.MarshalIndent(in, "", "  ")
-	fmt.Println(string(bytes))
+	str, err := RedactedJSON(in)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	if err := output.Write(os.Stdout, output.FormatJSON, json.RawMessage(str)); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
+
+// PrintStruct print in to stdout with format like json, yaml, csv, table or markdown
+//
+// Secrets are redacted the same way as PrintStructPretty, see RedactedJSON.
+// See output.ParseFormat for supported formats.
+func PrintStruct(in interface{}, format string) error {
+	f, err := output.ParseFormat(format)
+	if err != nil {
+		return err
+	}
+
+	return output.Write(os.Stdout, f, redactValue(in))
 }
 
 func StringP(in string) *string {
-	return &in
+	return Ptr(in)
 }
 
 func IntP(in int) *int {
-	return &in
+	return Ptr(in)
 }
 
 func BoolP(in bool) *bool {
-	return &in
+	return Ptr(in)
 }
 
 func Int32P(in int32) *int32 {
-	return &in
+	return Ptr(in)
 }
 
+// Uint64P converts int64 to *uint64, use Ptr(uint64(in)) for uint64
 func Uint64P(in int64) *uint64 {
-	u := uint64(in)
-	return &u
+	return Ptr(uint64(in))
 }
 
 func Int64P(in int64) *int64 {
-	u := int64(in)
-	return &u
+	return Ptr(in)
 }
 
+// DedupStringSlice remove duplicated strings, order of first occurrence is kept
 func DedupStringSlice(src []string) []string {
-	m := make(map[string]bool)
-	res := make([]string, 0)
-
-	for i := range src {
-		if _, exist := m[src[i]]; !exist {
-			m[src[i]] = true
-		}
-	}
-
-	for k, _ := range m {
-		res = append(res, k)
-	}
-
-	return res
+	return Dedup(src)
 }
 
+// DedupInt64Slice remove duplicated int64, order of first occurrence is kept
 func DedupInt64Slice(src []int64) []int64 {
-	m := make(map[int64]bool)
-	res := make([]int64, 0)
-
-	for i := range src {
-		if _, exist := m[src[i]]; !exist {
-			m[src[i]] = true
-		}
-	}
-
-	for k, _ := range m {
-		res = append(res, k)
-	}
-
-	return res
+	return Dedup(src)
 }
 
 func JoinStringPtr(src []*string) string {
@@ -88,13 +92,8 @@ func JoinStringPtr(src []*string) string {
 }
 
 func ContainsStringSlice(src []string, key string) (bool, int) {
-	for i := range src {
-		if src[i] == key {
-			return true, i
-		}
-	}
-
-	return false, -1
+	i := IndexOf(src, key)
+	return i >= 0, i
 }
 
 func RemoveSpace(src string) string {
@@ -104,19 +103,11 @@ func RemoveSpace(src string) string {
 }
 
 func FromStringPointer(in *string, de string) string {
-	if in == nil {
-		return de
-	}
-
-	return *in
+	return Deref(in, de)
 }
 
 func FromBoolPointer(in *bool, de bool) bool {
-	if in == nil {
-		return de
-	}
-
-	return *in
+	return Deref(in, de)
 }
 
 func FromFloat32Pointer(in *float32, de float64) float64 {
@@ -128,11 +119,7 @@ func FromFloat32Pointer(in *float32, de float64) float64 {
 }
 
 func FromFloat64Pointer(in *float64, de float64) float64 {
-	if in == nil {
-		return de
-	}
-
-	return *in
+	return Deref(in, de)
 }
 
 func StringWithDefault(in string, de string) string {
@@ -9,18 +9,81 @@ import (
 	"sort"
 )
 
+// Ordered types which support < operator, same as cmp.Ordered in newer Go
+type Ordered interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64 |
+		~string
+}
+
+// Pair key and value of map entry
+type Pair[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
+// SortedKeys returns keys of map in ascending order
+func SortedKeys[K Ordered, V any](m map[K]V) []K {
+	res := make([]K, 0, len(m))
+
+	for k := range m {
+		res = append(res, k)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i] < res[j]
+	})
+
+	return res
+}
+
+// Values returns values of map, ordered by key
+func Values[K Ordered, V any](m map[K]V) []V {
+	keys := SortedKeys(m)
+	res := make([]V, 0, len(keys))
+
+	for i := range keys {
+		res = append(res, m[keys[i]])
+	}
+
+	return res
+}
+
+// SortedPairs returns entries of map, ordered by key
+func SortedPairs[K Ordered, V any](m map[K]V) []Pair[K, V] {
+	keys := SortedKeys(m)
+	res := make([]Pair[K, V], 0, len(keys))
+
+	for i := range keys {
+		res = append(res, Pair[K, V]{Key: keys[i], Value: m[keys[i]]})
+	}
+
+	return res
+}
+
+// RangeSorted calls fn for each entry of map ordered by key, stops if fn returns false
+func RangeSorted[K Ordered, V any](m map[K]V, fn func(K, V) bool) {
+	keys := SortedKeys(m)
+
+	for i := range keys {
+		if !fn(keys[i], m[keys[i]]) {
+			return
+		}
+	}
+}
+
+// MapKeysString returns sorted keys of map whose key is string or named string type
+//
+// Use SortedKeys if type of map is known.
 func MapKeysString(in interface{}) []string {
 	val := reflect.ValueOf(in)
 
 	res := make([]string, 0)
 
-	if val.Kind() == reflect.Map {
+	if val.Kind() == reflect.Map && val.Type().Key().Kind() == reflect.String {
 		for _, e := range val.MapKeys() {
-			v := val.MapIndex(e)
-			switch t := v.Interface().(type) {
-			case string:
-				res = append(res, t)
-			}
+			res = append(res, e.String())
 		}
 	}
 
@@ -29,18 +92,17 @@ func MapKeysString(in interface{}) []string {
 	return res
 }
 
+// MapKeysInt64 returns sorted keys of map whose key is signed integer or named signed integer type
+//
+// Use SortedKeys if type of map is known.
 func MapKeysInt64(in interface{}) []int64 {
 	val := reflect.ValueOf(in)
 
 	res := make([]int64, 0)
 
-	if val.Kind() == reflect.Map {
+	if val.Kind() == reflect.Map && isIntKind(val.Type().Key().Kind()) {
 		for _, e := range val.MapKeys() {
-			v := val.MapIndex(e)
-			switch t := v.Interface().(type) {
-			case int64:
-				res = append(res, t)
-			}
+			res = append(res, e.Int())
 		}
 	}
 
@@ -51,24 +113,25 @@ func MapKeysInt64(in interface{}) []int64 {
 	return res
 }
 
+// MapKeysInt returns sorted keys of map whose key is signed integer or named signed integer type
+//
+// Use SortedKeys if type of map is known.
 func MapKeysInt(in interface{}) []int {
-	val := reflect.ValueOf(in)
-
-	res := make([]int, 0)
+	keys := MapKeysInt64(in)
 
-	if val.Kind() == reflect.Map {
-		for _, e := range val.MapKeys() {
-			v := val.MapIndex(e)
-			switch t := v.Interface().(type) {
-			case int:
-				res = append(res, t)
-			}
-		}
+	res := make([]int, 0, len(keys))
+	for i := range keys {
+		res = append(res, int(keys[i]))
 	}
 
-	sort.Slice(res, func(i, j int) bool {
-		return res[i] < res[j]
-	})
-
 	return res
 }
+
+func isIntKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,61 @@
+package pio
+
+import (
+	"reflect"
+	"testing"
+)
+
+type accountID string
+
+type monthIndex int32
+
+func TestSortedKeysAndValues(t *testing.T) {
+	m := map[string]float64{"2024-03": 3, "2024-01": 1, "2024-02": 2}
+
+	if got := SortedKeys(m); !reflect.DeepEqual(got, []string{"2024-01", "2024-02", "2024-03"}) {
+		t.Errorf("got %v, wanted %v", got, []string{"2024-01", "2024-02", "2024-03"})
+	}
+
+	if got := Values(m); !reflect.DeepEqual(got, []float64{1, 2, 3}) {
+		t.Errorf("got %v, wanted %v", got, []float64{1, 2, 3})
+	}
+
+	pairs := SortedPairs(m)
+	if len(pairs) != 3 || pairs[0].Key != "2024-01" || pairs[2].Value != 3 {
+		t.Errorf("got %v, wanted sorted pairs", pairs)
+	}
+
+	visited := make([]string, 0)
+	RangeSorted(m, func(k string, v float64) bool {
+		visited = append(visited, k)
+		return len(visited) < 2
+	})
+	if !reflect.DeepEqual(visited, []string{"2024-01", "2024-02"}) {
+		t.Errorf("got %v, wanted %v", visited, []string{"2024-01", "2024-02"})
+	}
+}
+
+func TestMapKeysReflection(t *testing.T) {
+	costs := map[string]float64{"b": 2, "a": 1}
+	if got := MapKeysString(costs); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("got %v, wanted %v", got, []string{"a", "b"})
+	}
+
+	named := map[accountID]int{"y": 1, "x": 2}
+	if got := MapKeysString(named); !reflect.DeepEqual(got, []string{"x", "y"}) {
+		t.Errorf("got %v, wanted %v", got, []string{"x", "y"})
+	}
+
+	ints := map[monthIndex]string{3: "c", 1: "a"}
+	if got := MapKeysInt(ints); !reflect.DeepEqual(got, []int{1, 3}) {
+		t.Errorf("got %v, wanted %v", got, []int{1, 3})
+	}
+
+	if got := MapKeysInt64(map[int64]bool{5: true, -1: false}); !reflect.DeepEqual(got, []int64{-1, 5}) {
+		t.Errorf("got %v, wanted %v", got, []int64{-1, 5})
+	}
+
+	if got := MapKeysInt64(costs); len(got) != 0 {
+		t.Errorf("got %v, wanted empty", got)
+	}
+}
@@ -0,0 +1,162 @@
+/*
+ * Copyright (c) 2022 The Mof Authors
+ */
+
+package pio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// LineError error of a single line in NDJSON stream, Line starts from 1
+type LineError struct {
+	Line int
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("ndjson line %d: %v", e.Line, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// NDJSONDecoder reads newline-delimited JSON one line at a time
+//
+// Lines are not limited in length, blank lines are skipped. Usage:
+//
+//	dec := NewNDJSONDecoder[Item](r)
+//	for dec.Next() {
+//		item := dec.Value()
+//	}
+//	if err := dec.Err(); err != nil {
+//	}
+type NDJSONDecoder[T any] struct {
+	r     *bufio.Reader
+	line  int
+	value T
+	err   error
+	done  bool
+}
+
+// NewNDJSONDecoder create NDJSONDecoder, r is buffered internally
+func NewNDJSONDecoder[T any](r io.Reader) *NDJSONDecoder[T] {
+	return &NDJSONDecoder[T]{
+		r: bufio.NewReader(r),
+	}
+}
+
+// Next decode next line, returns false at end of stream or on error, check Err afterwards
+func (d *NDJSONDecoder[T]) Next() bool {
+	if d.done {
+		return false
+	}
+
+	for {
+		b, err := d.r.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			d.err = &LineError{Line: d.line + 1, Err: err}
+			d.done = true
+			return false
+		}
+
+		if len(b) > 0 {
+			d.line++
+		}
+
+		if b = bytes.TrimSpace(b); len(b) > 0 {
+			var value T
+			if uErr := json.Unmarshal(b, &value); uErr != nil {
+				d.err = &LineError{Line: d.line, Err: uErr}
+				d.done = true
+				return false
+			}
+
+			d.value = value
+			d.done = err == io.EOF
+			return true
+		}
+
+		if err == io.EOF {
+			d.done = true
+			return false
+		}
+	}
+}
+
+// Value returns value decoded by last Next
+func (d *NDJSONDecoder[T]) Value() T {
+	return d.value
+}
+
+// Err returns first error met, nil at end of stream
+func (d *NDJSONDecoder[T]) Err() error {
+	return d.err
+}
+
+// Line returns line number of last decoded value, starts from 1
+func (d *NDJSONDecoder[T]) Line() int {
+	return d.line
+}
+
+// DecodeNDJSON calls fn for each value in NDJSON stream, stops at first error
+//
+// Error from fn is wrapped with LineError as well.
+func DecodeNDJSON[T any](r io.Reader, fn func(T) error) error {
+	dec := NewNDJSONDecoder[T](r)
+
+	for dec.Next() {
+		if err := fn(dec.Value()); err != nil {
+			return &LineError{Line: dec.Line(), Err: err}
+		}
+	}
+
+	return dec.Err()
+}
+
+// NDJSONWriter writes newline-delimited JSON with buffer, Flush must be called at the end
+type NDJSONWriter struct {
+	w     *bufio.Writer
+	lines int
+}
+
+// NewNDJSONWriter create NDJSONWriter on top of w
+func NewNDJSONWriter(w io.Writer) *NDJSONWriter {
+	return &NDJSONWriter{
+		w: bufio.NewWriter(w),
+	}
+}
+
+// Write marshal v as one line, nothing is written if v fails to marshal
+func (w *NDJSONWriter) Write(v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return &LineError{Line: w.lines + 1, Err: err}
+	}
+
+	if _, err := w.w.Write(b); err != nil {
+		return &LineError{Line: w.lines + 1, Err: err}
+	}
+
+	if err := w.w.WriteByte('\n'); err != nil {
+		return &LineError{Line: w.lines + 1, Err: err}
+	}
+
+	w.lines++
+	return nil
+}
+
+// Flush writes buffered lines to underlying io.Writer
+func (w *NDJSONWriter) Flush() error {
+	return w.w.Flush()
+}
+
+// Lines number of lines written
+func (w *NDJSONWriter) Lines() int {
+	return w.lines
+}
@@ -0,0 +1,81 @@
+package pio
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type lineItem struct {
+	Name string  `json:"name"`
+	Cost float64 `json:"cost"`
+}
+
+func TestNDJSONRoundTrip(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := NewNDJSONWriter(buf)
+
+	for _, item := range []lineItem{{Name: "a", Cost: 1}, {Name: "b", Cost: 2.5}} {
+		if err := w.Write(item); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := w.Write(func() {}); err == nil {
+		t.Errorf("got nil, wanted marshal error")
+	}
+
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	wanted := "{\"name\":\"a\",\"cost\":1}\n{\"name\":\"b\",\"cost\":2.5}\n"
+	if got := buf.String(); got != wanted || w.Lines() != 2 {
+		t.Errorf("got %q %d, wanted %q 2", got, w.Lines(), wanted)
+	}
+
+	res := make([]lineItem, 0)
+	err := DecodeNDJSON(strings.NewReader(buf.String()), func(item lineItem) error {
+		res = append(res, item)
+		return nil
+	})
+	if err != nil || len(res) != 2 || res[1].Cost != 2.5 {
+		t.Errorf("got %v %v, wanted 2 items", res, err)
+	}
+}
+
+func TestNDJSONDecoder(t *testing.T) {
+	in := "{\"name\":\"a\"}\r\n\n   \n{\"name\":\"b\"}\n{bad}\n{\"name\":\"c\"}"
+
+	dec := NewNDJSONDecoder[lineItem](strings.NewReader(in))
+
+	names := make([]string, 0)
+	lines := make([]int, 0)
+	for dec.Next() {
+		names = append(names, dec.Value().Name)
+		lines = append(lines, dec.Line())
+	}
+
+	if strings.Join(names, ",") != "a,b" || lines[1] != 4 {
+		t.Errorf("got %v %v, wanted [a b] [1 4]", names, lines)
+	}
+
+	lineErr := &LineError{}
+	if err := dec.Err(); !errors.As(err, &lineErr) || lineErr.Line != 5 {
+		t.Errorf("got %v, wanted error of line 5", err)
+	}
+
+	dec = NewNDJSONDecoder[lineItem](strings.NewReader("{\"name\":\"x\"}"))
+	if !dec.Next() || dec.Value().Name != "x" || dec.Next() || dec.Err() != nil {
+		t.Errorf("got %v, wanted last line without newline", dec.Err())
+	}
+
+	stop := errors.New("stop")
+	err := DecodeNDJSON(strings.NewReader(in), func(item lineItem) error {
+		return stop
+	})
+	if !errors.Is(err, stop) || !errors.As(err, &lineErr) || lineErr.Line != 1 {
+		t.Errorf("got %v, wanted stop at line 1", err)
+	}
+}
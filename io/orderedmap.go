@@ -0,0 +1,330 @@
+/*
+ * Copyright (c) 2022 The Mof Authors
+ */
+
+package pio
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// OrderedMap map which keeps insertion order of keys
+//
+// Order could be changed on demand by SortKeys. When marshalled to JSON or YAML,
+// entries are written as object in current order, and order of source document
+// is kept when unmarshalled.
+//
+// Zero value is ready to use, OrderedMap is not safe for concurrent use.
+type OrderedMap[K comparable, V any] struct {
+	keys   []K
+	values map[K]V
+}
+
+// NewOrderedMap create an empty OrderedMap
+func NewOrderedMap[K comparable, V any]() *OrderedMap[K, V] {
+	return &OrderedMap[K, V]{
+		keys:   make([]K, 0),
+		values: make(map[K]V),
+	}
+}
+
+// NewOrderedMapFromMap create OrderedMap from map, entries are ordered by key
+func NewOrderedMapFromMap[K Ordered, V any](m map[K]V) *OrderedMap[K, V] {
+	res := NewOrderedMap[K, V]()
+
+	RangeSorted(m, func(k K, v V) bool {
+		res.Set(k, v)
+		return true
+	})
+
+	return res
+}
+
+// Set add or update entry, updated entry keeps its position
+func (m *OrderedMap[K, V]) Set(key K, value V) {
+	if m.values == nil {
+		m.values = make(map[K]V)
+	}
+
+	if _, exist := m.values[key]; !exist {
+		m.keys = append(m.keys, key)
+	}
+
+	m.values[key] = value
+}
+
+// Get returns value of key and whether key exists
+func (m *OrderedMap[K, V]) Get(key K) (V, bool) {
+	v, ok := m.values[key]
+	return v, ok
+}
+
+// Has checks whether key exists
+func (m *OrderedMap[K, V]) Has(key K) bool {
+	_, ok := m.values[key]
+	return ok
+}
+
+// Delete remove entry of key, nothing happens if key does not exist
+func (m *OrderedMap[K, V]) Delete(key K) {
+	if _, exist := m.values[key]; !exist {
+		return
+	}
+
+	delete(m.values, key)
+	if i := IndexOf(m.keys, key); i >= 0 {
+		m.keys = append(m.keys[:i], m.keys[i+1:]...)
+	}
+}
+
+// Len number of entries
+func (m *OrderedMap[K, V]) Len() int {
+	return len(m.keys)
+}
+
+// Keys returns a copy of keys in current order
+func (m *OrderedMap[K, V]) Keys() []K {
+	res := make([]K, len(m.keys))
+	copy(res, m.keys)
+	return res
+}
+
+// Values returns values in current order
+func (m *OrderedMap[K, V]) Values() []V {
+	res := make([]V, 0, len(m.keys))
+
+	for i := range m.keys {
+		res = append(res, m.values[m.keys[i]])
+	}
+
+	return res
+}
+
+// Pairs returns entries in current order
+func (m *OrderedMap[K, V]) Pairs() []Pair[K, V] {
+	res := make([]Pair[K, V], 0, len(m.keys))
+
+	for i := range m.keys {
+		res = append(res, Pair[K, V]{Key: m.keys[i], Value: m.values[m.keys[i]]})
+	}
+
+	return res
+}
+
+// Range calls fn for each entry in current order, stops if fn returns false
+func (m *OrderedMap[K, V]) Range(fn func(K, V) bool) {
+	for _, k := range m.Keys() {
+		if !fn(k, m.values[k]) {
+			return
+		}
+	}
+}
+
+// SortKeys reorder entries by less, sort is stable
+func (m *OrderedMap[K, V]) SortKeys(less func(a, b K) bool) {
+	sort.SliceStable(m.keys, func(i, j int) bool {
+		return less(m.keys[i], m.keys[j])
+	})
+}
+
+// MarshalJSON writes entries as JSON object in current order
+func (m OrderedMap[K, V]) MarshalJSON() ([]byte, error) {
+	buf := bytes.Buffer{}
+	buf.WriteByte('{')
+
+	for i := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		k, err := keyToString(m.keys[i])
+		if err != nil {
+			return nil, err
+		}
+
+		kb, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+
+		vb, err := json.Marshal(m.values[m.keys[i]])
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(kb)
+		buf.WriteByte(':')
+		buf.Write(vb)
+	}
+
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON reads JSON object and keeps order of its keys, existing entries are dropped
+func (m *OrderedMap[K, V]) UnmarshalJSON(b []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	*m = *NewOrderedMap[K, V]()
+
+	if tok == nil {
+		// null
+		return nil
+	}
+
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return errors.New(fmt.Sprintf("cannot unmarshal %v into ordered map, object expected", tok))
+	}
+
+	for dec.More() {
+		tok, err = dec.Token()
+		if err != nil {
+			return err
+		}
+
+		var key K
+		if err := stringToKey(tok.(string), &key); err != nil {
+			return err
+		}
+
+		var value V
+		if err := dec.Decode(&value); err != nil {
+			return err
+		}
+
+		m.Set(key, value)
+	}
+
+	// closing }
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MarshalYAML writes entries as YAML mapping in current order
+func (m OrderedMap[K, V]) MarshalYAML() (interface{}, error) {
+	res := &yaml.Node{
+		Kind:    yaml.MappingNode,
+		Tag:     "!!map",
+		Content: make([]*yaml.Node, 0, len(m.keys)*2),
+	}
+
+	for i := range m.keys {
+		k := &yaml.Node{}
+		if err := k.Encode(m.keys[i]); err != nil {
+			return nil, err
+		}
+
+		v := &yaml.Node{}
+		if err := v.Encode(m.values[m.keys[i]]); err != nil {
+			return nil, err
+		}
+
+		res.Content = append(res.Content, k, v)
+	}
+
+	return res, nil
+}
+
+// UnmarshalYAML reads YAML mapping and keeps order of its keys, existing entries are dropped
+func (m *OrderedMap[K, V]) UnmarshalYAML(value *yaml.Node) error {
+	for value.Kind == yaml.DocumentNode && len(value.Content) > 0 {
+		value = value.Content[0]
+	}
+
+	if value.Kind == yaml.AliasNode && value.Alias != nil {
+		value = value.Alias
+	}
+
+	*m = *NewOrderedMap[K, V]()
+
+	if value.Kind == yaml.ScalarNode && value.Tag == "!!null" {
+		return nil
+	}
+
+	if value.Kind != yaml.MappingNode {
+		return errors.New(fmt.Sprintf("cannot unmarshal yaml line %d into ordered map, mapping expected", value.Line))
+	}
+
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		var key K
+		if err := value.Content[i].Decode(&key); err != nil {
+			return err
+		}
+
+		var v V
+		if err := value.Content[i+1].Decode(&v); err != nil {
+			return err
+		}
+
+		m.Set(key, v)
+	}
+
+	return nil
+}
+
+// keyToString convert key to JSON object key, same rules as encoding/json for map keys
+func keyToString(key interface{}) (string, error) {
+	if tm, ok := key.(encoding.TextMarshaler); ok {
+		b, err := tm.MarshalText()
+		return string(b), err
+	}
+
+	v := reflect.ValueOf(key)
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	}
+
+	return "", errors.New(fmt.Sprintf("unsupported key type of ordered map: %T", key))
+}
+
+// stringToKey parse JSON object key into key, same rules as encoding/json for map keys
+func stringToKey(str string, key interface{}) error {
+	if tu, ok := key.(encoding.TextUnmarshaler); ok {
+		return tu.UnmarshalText([]byte(str))
+	}
+
+	v := reflect.ValueOf(key).Elem()
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(str)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(str, 10, v.Type().Bits())
+		if err != nil {
+			return errors.New(fmt.Sprintf("invalid key of ordered map: %q, %v", str, err))
+		}
+		v.SetInt(n)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(str, 10, v.Type().Bits())
+		if err != nil {
+			return errors.New(fmt.Sprintf("invalid key of ordered map: %q, %v", str, err))
+		}
+		v.SetUint(n)
+		return nil
+	}
+
+	return errors.New(fmt.Sprintf("unsupported key type of ordered map: %s", v.Type()))
+}
@@ -0,0 +1,96 @@
+package pio
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestOrderedMapKeepsInsertionOrder(t *testing.T) {
+	m := NewOrderedMap[string, float64]()
+	m.Set("2024-03", 3)
+	m.Set("2024-01", 1)
+	m.Set("2024-02", 2)
+	m.Set("2024-03", 30)
+
+	if got := m.Keys(); !reflect.DeepEqual(got, []string{"2024-03", "2024-01", "2024-02"}) {
+		t.Errorf("got %v, wanted %v", got, []string{"2024-03", "2024-01", "2024-02"})
+	}
+
+	if v, ok := m.Get("2024-03"); !ok || v != 30 {
+		t.Errorf("got %v %v, wanted 30 true", v, ok)
+	}
+
+	m.Delete("2024-01")
+	if m.Has("2024-01") || m.Len() != 2 {
+		t.Errorf("got %v, wanted 2024-01 deleted", m.Keys())
+	}
+
+	m.SortKeys(func(a, b string) bool {
+		return a < b
+	})
+	if got := m.Values(); !reflect.DeepEqual(got, []float64{2, 30}) {
+		t.Errorf("got %v, wanted %v", got, []float64{2, 30})
+	}
+}
+
+func TestOrderedMapJSON(t *testing.T) {
+	m := &OrderedMap[string, int]{}
+	if err := json.Unmarshal([]byte(`{"b": 2, "c": 3, "a": 1}`), m); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, wanted := string(b), `{"b":2,"c":3,"a":1}`; got != wanted {
+		t.Errorf("got %q, wanted %q", got, wanted)
+	}
+
+	ints := NewOrderedMapFromMap(map[int]string{10: "x", 2: "y"})
+	b, err = json.Marshal(ints)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, wanted := string(b), `{"2":"y","10":"x"}`; got != wanted {
+		t.Errorf("got %q, wanted %q", got, wanted)
+	}
+
+	back := NewOrderedMap[int, string]()
+	if err := json.Unmarshal(b, back); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := back.Keys(); !reflect.DeepEqual(got, []int{2, 10}) {
+		t.Errorf("got %v, wanted %v", got, []int{2, 10})
+	}
+
+	if err := json.Unmarshal([]byte(`[1]`), back); err == nil {
+		t.Errorf("got nil, wanted error for array")
+	}
+}
+
+func TestOrderedMapYAML(t *testing.T) {
+	m := &OrderedMap[string, int]{}
+	if err := yaml.Unmarshal([]byte("b: 2\nc: 3\na: 1\n"), m); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := m.Keys(); !reflect.DeepEqual(got, []string{"b", "c", "a"}) {
+		t.Errorf("got %v, wanted %v", got, []string{"b", "c", "a"})
+	}
+
+	b, err := yaml.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, wanted := string(b), "b: 2\nc: 3\na: 1\n"; got != wanted {
+		t.Errorf("got %q, wanted %q", got, wanted)
+	}
+}
@@ -0,0 +1,474 @@
+/*
+ * Copyright (c) 2022 The Mof Authors
+ */
+
+package pio
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ****** JSON Merge Patch, RFC 7386 ******
+
+// MergePatch apply merge patch to doc, null in patch removes the key
+func MergePatch(doc, patch []byte) ([]byte, error) {
+	target, err := decodeSingleJSON(doc)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("invalid document: %v", err))
+	}
+
+	p, err := decodeSingleJSON(patch)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("invalid merge patch: %v", err))
+	}
+
+	return json.Marshal(mergePatchValue(target, p))
+}
+
+// CreateMergePatch returns merge patch which turns original into modified
+//
+// Error is returned if modified has object member with null value, which
+// cannot be expressed by merge patch since null means removal.
+func CreateMergePatch(original, modified []byte) ([]byte, error) {
+	o, err := decodeSingleJSON(original)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("invalid original document: %v", err))
+	}
+
+	m, err := decodeSingleJSON(modified)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("invalid modified document: %v", err))
+	}
+
+	p, err := createMergePatchValue(o, m, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(p)
+}
+
+func mergePatchValue(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = make(map[string]interface{})
+	}
+
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+			continue
+		}
+
+		t[k] = mergePatchValue(t[k], v)
+	}
+
+	return t
+}
+
+func createMergePatchValue(original, modified interface{}, path string) (interface{}, error) {
+	o, oOk := original.(map[string]interface{})
+	m, mOk := modified.(map[string]interface{})
+	if !oOk || !mOk {
+		if err := checkNoNullMember(modified, path); err != nil {
+			return nil, err
+		}
+		return modified, nil
+	}
+
+	res := make(map[string]interface{})
+
+	for k := range o {
+		if _, ok := m[k]; !ok {
+			res[k] = nil
+		}
+	}
+
+	for k, v := range m {
+		childPath := path + FormatJSONPointer([]string{k})
+
+		if ov, ok := o[k]; ok && equalJSON(ov, v) {
+			continue
+		}
+
+		if v == nil {
+			return nil, errors.New(fmt.Sprintf("cannot create merge patch: %s is null in modified document", childPath))
+		}
+
+		if ov, ok := o[k]; ok {
+			p, err := createMergePatchValue(ov, v, childPath)
+			if err != nil {
+				return nil, err
+			}
+			res[k] = p
+			continue
+		}
+
+		if err := checkNoNullMember(v, childPath); err != nil {
+			return nil, err
+		}
+		res[k] = v
+	}
+
+	return res, nil
+}
+
+// checkNoNullMember object member with null value would be removed when patch is applied
+func checkNoNullMember(v interface{}, path string) error {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	for k := range m {
+		childPath := path + FormatJSONPointer([]string{k})
+		if m[k] == nil {
+			return errors.New(fmt.Sprintf("cannot create merge patch: %s is null in modified document", childPath))
+		}
+
+		if err := checkNoNullMember(m[k], childPath); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ****** JSON Patch, RFC 6902 ******
+
+const (
+	PatchAdd     = "add"
+	PatchRemove  = "remove"
+	PatchReplace = "replace"
+	PatchMove    = "move"
+	PatchCopy    = "copy"
+	PatchTest    = "test"
+)
+
+// PatchOperation single operation of JSON Patch
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// ApplyJSONPatch apply JSON Patch to doc, nothing is applied if any operation fails
+func ApplyJSONPatch(doc, patch []byte) ([]byte, error) {
+	target, err := decodeSingleJSON(doc)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("invalid document: %v", err))
+	}
+
+	ops := make([]PatchOperation, 0)
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, errors.New(fmt.Sprintf("invalid JSON patch: %v", err))
+	}
+
+	for i := range ops {
+		target, err = applyPatchOperation(target, &ops[i])
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("failed to apply JSON patch operation %d (%s %s): %v", i, ops[i].Op, ops[i].Path, err))
+		}
+	}
+
+	return json.Marshal(target)
+}
+
+func applyPatchOperation(doc interface{}, op *PatchOperation) (interface{}, error) {
+	path, err := ParseJSONPointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case PatchAdd, PatchReplace, PatchTest:
+		if op.Value == nil {
+			return nil, errors.New("value is required")
+		}
+
+		value, err := decodeSingleJSON(op.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		switch op.Op {
+		case PatchAdd:
+			return addJSON(doc, path, value)
+		case PatchReplace:
+			return replaceJSON(doc, path, value)
+		}
+
+		curr, err := lookupJSON(doc, path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !equalJSON(curr, value) {
+			return nil, errors.New("test failed, value is different")
+		}
+
+		return doc, nil
+	case PatchRemove:
+		res, _, err := removeJSON(doc, path)
+		return res, err
+	case PatchMove, PatchCopy:
+		from, err := ParseJSONPointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+
+		if op.Op == PatchCopy {
+			value, err := lookupJSON(doc, from)
+			if err != nil {
+				return nil, err
+			}
+
+			return addJSON(doc, path, copyJSON(value))
+		}
+
+		if isProperPrefix(from, path) {
+			return nil, errors.New(fmt.Sprintf("cannot move %s into its child %s", op.From, op.Path))
+		}
+
+		doc, value, err := removeJSON(doc, from)
+		if err != nil {
+			return nil, err
+		}
+
+		return addJSON(doc, path, value)
+	}
+
+	return nil, errors.New(fmt.Sprintf("unsupported op: %q", op.Op))
+}
+
+func addJSON(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) < 1 {
+		return value, nil
+	}
+
+	return updateJSON(doc, path, func(parent interface{}, last string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			node[last] = value
+			return node, nil
+		case []interface{}:
+			idx, err := arrayIndex(last, len(node), true)
+			if err != nil {
+				return nil, err
+			}
+
+			node = append(node, nil)
+			copy(node[idx+1:], node[idx:])
+			node[idx] = value
+			return node, nil
+		}
+
+		return nil, errors.New(fmt.Sprintf("parent of %s is not object or array", FormatJSONPointer(path)))
+	})
+}
+
+func replaceJSON(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) < 1 {
+		return value, nil
+	}
+
+	return updateJSON(doc, path, func(parent interface{}, last string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			if _, ok := node[last]; !ok {
+				return nil, errors.New(fmt.Sprintf("path not found: %s", FormatJSONPointer(path)))
+			}
+			node[last] = value
+			return node, nil
+		case []interface{}:
+			idx, err := arrayIndex(last, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			node[idx] = value
+			return node, nil
+		}
+
+		return nil, errors.New(fmt.Sprintf("parent of %s is not object or array", FormatJSONPointer(path)))
+	})
+}
+
+// removeJSON remove value at path, returns updated doc and removed value
+func removeJSON(doc interface{}, path []string) (interface{}, interface{}, error) {
+	if len(path) < 1 {
+		return nil, nil, errors.New("cannot remove whole document")
+	}
+
+	var removed interface{}
+	res, err := updateJSON(doc, path, func(parent interface{}, last string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			v, ok := node[last]
+			if !ok {
+				return nil, errors.New(fmt.Sprintf("path not found: %s", FormatJSONPointer(path)))
+			}
+			removed = v
+			delete(node, last)
+			return node, nil
+		case []interface{}:
+			idx, err := arrayIndex(last, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			removed = node[idx]
+			return append(node[:idx], node[idx+1:]...), nil
+		}
+
+		return nil, errors.New(fmt.Sprintf("parent of %s is not object or array", FormatJSONPointer(path)))
+	})
+
+	return res, removed, err
+}
+
+func isProperPrefix(prefix, path []string) bool {
+	if len(prefix) >= len(path) {
+		return false
+	}
+
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ****** Diff ******
+
+type ChangeOp string
+
+const (
+	ChangeAdd     ChangeOp = "add"
+	ChangeRemove  ChangeOp = "remove"
+	ChangeReplace ChangeOp = "replace"
+)
+
+// Change single difference between two documents
+//
+// Path is JSON Pointer, From is empty for add and To is empty for remove.
+type Change struct {
+	Op   ChangeOp    `json:"op"`
+	Path string      `json:"path"`
+	From interface{} `json:"from,omitempty"`
+	To   interface{} `json:"to,omitempty"`
+}
+
+// Diff compare Go values by their JSON, see DiffJSON
+func Diff(a, b interface{}) ([]Change, error) {
+	ra, err := MarshalJSON(a)
+	if err != nil {
+		return nil, err
+	}
+
+	rb, err := MarshalJSON(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return DiffJSON([]byte(nullIfEmpty(ra)), []byte(nullIfEmpty(rb)))
+}
+
+// DiffJSON returns added, removed and changed paths from a to b
+//
+// Objects are compared by key in sorted order, arrays are compared by index,
+// extra elements are reported as added or removed. Numbers are compared by
+// value, so 1.0 equals 1.
+func DiffJSON(a, b []byte) ([]Change, error) {
+	va, err := decodeSingleJSON(a)
+	if err != nil {
+		return nil, err
+	}
+
+	vb, err := decodeSingleJSON(b)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]Change, 0)
+	diffJSONValue(va, vb, []string{}, &res)
+	return res, nil
+}
+
+func diffJSONValue(a, b interface{}, path []string, res *[]Change) {
+	switch na := a.(type) {
+	case map[string]interface{}:
+		nb, ok := b.(map[string]interface{})
+		if !ok {
+			break
+		}
+
+		keys := make([]string, 0, len(na)+len(nb))
+		for k := range na {
+			keys = append(keys, k)
+		}
+		for k := range nb {
+			if _, ok := na[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			childPath := append(append([]string{}, path...), k)
+
+			va, inA := na[k]
+			vb, inB := nb[k]
+			switch {
+			case !inB:
+				*res = append(*res, Change{Op: ChangeRemove, Path: FormatJSONPointer(childPath), From: va})
+			case !inA:
+				*res = append(*res, Change{Op: ChangeAdd, Path: FormatJSONPointer(childPath), To: vb})
+			default:
+				diffJSONValue(va, vb, childPath, res)
+			}
+		}
+		return
+	case []interface{}:
+		nb, ok := b.([]interface{})
+		if !ok {
+			break
+		}
+
+		for i := 0; i < len(na) || i < len(nb); i++ {
+			childPath := append(append([]string{}, path...), fmt.Sprint(i))
+
+			switch {
+			case i >= len(nb):
+				*res = append(*res, Change{Op: ChangeRemove, Path: FormatJSONPointer(childPath), From: na[i]})
+			case i >= len(na):
+				*res = append(*res, Change{Op: ChangeAdd, Path: FormatJSONPointer(childPath), To: nb[i]})
+			default:
+				diffJSONValue(na[i], nb[i], childPath, res)
+			}
+		}
+		return
+	}
+
+	if !equalJSON(a, b) {
+		*res = append(*res, Change{Op: ChangeReplace, Path: FormatJSONPointer(path), From: a, To: b})
+	}
+}
+
+// nullIfEmpty MarshalJSON returns empty string for nil
+func nullIfEmpty(str string) string {
+	if str == "" {
+		return "null"
+	}
+
+	return str
+}
@@ -0,0 +1,217 @@
+package pio
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMergePatch(t *testing.T) {
+	// from RFC 7386 appendix A
+	tests := []struct {
+		doc    string
+		patch  string
+		wanted string
+	}{
+		{doc: `{"a":"b"}`, patch: `{"a":"c"}`, wanted: `{"a":"c"}`},
+		{doc: `{"a":"b"}`, patch: `{"b":"c"}`, wanted: `{"a":"b","b":"c"}`},
+		{doc: `{"a":"b"}`, patch: `{"a":null}`, wanted: `{}`},
+		{doc: `{"a":"b","b":"c"}`, patch: `{"a":null}`, wanted: `{"b":"c"}`},
+		{doc: `{"a":["b"]}`, patch: `{"a":"c"}`, wanted: `{"a":"c"}`},
+		{doc: `{"a":{"b":"c"}}`, patch: `{"a":{"b":"d","c":null}}`, wanted: `{"a":{"b":"d"}}`},
+		{doc: `{"a":[{"b":"c"}]}`, patch: `{"a":[1]}`, wanted: `{"a":[1]}`},
+		{doc: `["a","b"]`, patch: `["c","d"]`, wanted: `["c","d"]`},
+		{doc: `{"a":"foo"}`, patch: `null`, wanted: `null`},
+		{doc: `{"e":null}`, patch: `{"a":1}`, wanted: `{"a":1,"e":null}`},
+		{doc: `[1,2]`, patch: `{"a":"b","c":null}`, wanted: `{"a":"b"}`},
+		{doc: `{}`, patch: `{"a":{"bb":{"ccc":null}}}`, wanted: `{"a":{"bb":{}}}`},
+	}
+
+	for _, tt := range tests {
+		got, err := MergePatch([]byte(tt.doc), []byte(tt.patch))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(got) != tt.wanted {
+			t.Errorf("got %s, wanted %s", got, tt.wanted)
+		}
+	}
+}
+
+func TestCreateMergePatch(t *testing.T) {
+	original := `{"title":"Goodbye!","author":{"givenName":"John","familyName":"Doe"},"tags":["example","sample"],"content":"text","cost":1.50}`
+	modified := `{"title":"Hello!","author":{"givenName":"John"},"tags":["example"],"content":"text","phoneNumber":"+01-123-456-7890","cost":1.5}`
+
+	patch, err := CreateMergePatch([]byte(original), []byte(modified))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wanted := `{"author":{"familyName":null},"phoneNumber":"+01-123-456-7890","tags":["example"],"title":"Hello!"}`
+	if string(patch) != wanted {
+		t.Errorf("got %s, wanted %s", patch, wanted)
+	}
+
+	applied, err := MergePatch([]byte(original), patch)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if changes, _ := DiffJSON(applied, []byte(modified)); len(changes) != 0 {
+		t.Errorf("got %v, wanted no difference after applying patch", changes)
+	}
+
+	if _, err := CreateMergePatch([]byte(`{"a":1}`), []byte(`{"a":null}`)); err == nil {
+		t.Errorf("got nil, wanted error for null member")
+	}
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	tests := []struct {
+		doc    string
+		patch  string
+		wanted string
+	}{
+		{
+			doc:    `{"foo":"bar"}`,
+			patch:  `[{"op":"add","path":"/baz","value":"qux"}]`,
+			wanted: `{"baz":"qux","foo":"bar"}`,
+		},
+		{
+			doc:    `{"foo":["bar","baz"]}`,
+			patch:  `[{"op":"add","path":"/foo/1","value":"qux"},{"op":"add","path":"/foo/-","value":null}]`,
+			wanted: `{"foo":["bar","qux","baz",null]}`,
+		},
+		{
+			doc:    `{"baz":"qux","foo":"bar"}`,
+			patch:  `[{"op":"remove","path":"/baz"},{"op":"replace","path":"/foo","value":"boo"}]`,
+			wanted: `{"foo":"boo"}`,
+		},
+		{
+			doc:    `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`,
+			patch:  `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			wanted: `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`,
+		},
+		{
+			doc:    `{"foo":["all","grass","cows","eat"]}`,
+			patch:  `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`,
+			wanted: `{"foo":["all","cows","eat","grass"]}`,
+		},
+		{
+			doc:    `{"a/b":{"m~n":1}}`,
+			patch:  `[{"op":"test","path":"/a~1b/m~0n","value":1.0},{"op":"copy","from":"/a~1b","path":"/c"}]`,
+			wanted: `{"a/b":{"m~n":1},"c":{"m~n":1}}`,
+		},
+		{
+			doc:    `{"foo":"bar"}`,
+			patch:  `[{"op":"replace","path":"","value":[1]}]`,
+			wanted: `[1]`,
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := ApplyJSONPatch([]byte(tt.doc), []byte(tt.patch))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.patch, err)
+		}
+
+		if string(got) != tt.wanted {
+			t.Errorf("got %s, wanted %s", got, tt.wanted)
+		}
+	}
+
+	failures := []string{
+		`[{"op":"test","path":"/foo","value":"baz"}]`,
+		`[{"op":"remove","path":"/missing"}]`,
+		`[{"op":"add","path":"/arr/5","value":1}]`,
+		`[{"op":"add","path":"/arr/01","value":1}]`,
+		`[{"op":"move","from":"/obj","path":"/obj/child"}]`,
+		`[{"op":"add","path":"/foo"}]`,
+		`[{"op":"unknown","path":"/foo"}]`,
+		`[{"op":"add","path":"foo","value":1}]`,
+	}
+
+	for _, patch := range failures {
+		if _, err := ApplyJSONPatch([]byte(`{"foo":"bar","arr":[1],"obj":{}}`), []byte(patch)); err == nil {
+			t.Errorf("got nil, wanted error for %s", patch)
+		}
+	}
+
+	for patch, wanted := range map[string]string{
+		`[{"op":"add","path":"/obj/x/y","value":1}]`: "path not found: /obj/x",
+		`[{"op":"add","path":"/arr/3/y","value":1}]`: "path not found: /arr/3",
+		`[{"op":"add","path":"/foo/x/y","value":1}]`: "path not found: /foo/x",
+	} {
+		_, err := ApplyJSONPatch([]byte(`{"foo":"bar","arr":[1],"obj":{}}`), []byte(patch))
+		if err == nil || !strings.Contains(err.Error(), wanted) {
+			t.Errorf("got %v, wanted error mentioning %q", err, wanted)
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	type snapshot struct {
+		Month string             `json:"month"`
+		Costs map[string]float64 `json:"costs"`
+		Tags  []string           `json:"tags"`
+	}
+
+	a := snapshot{Month: "2024-01", Costs: map[string]float64{"ec2": 1, "s3": 2}, Tags: []string{"a", "b"}}
+	b := snapshot{Month: "2024-02", Costs: map[string]float64{"ec2": 1, "rds": 3}, Tags: []string{"a"}}
+
+	got, err := Diff(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	paths := make([]string, 0)
+	ops := make([]ChangeOp, 0)
+	for i := range got {
+		paths = append(paths, got[i].Path)
+		ops = append(ops, got[i].Op)
+	}
+
+	wantedPaths := []string{"/costs/rds", "/costs/s3", "/month", "/tags/1"}
+	wantedOps := []ChangeOp{ChangeAdd, ChangeRemove, ChangeReplace, ChangeRemove}
+	if !reflect.DeepEqual(paths, wantedPaths) || !reflect.DeepEqual(ops, wantedOps) {
+		t.Errorf("got %v %v, wanted %v %v", paths, ops, wantedPaths, wantedOps)
+	}
+
+	if got[2].From != "2024-01" || got[2].To != "2024-02" {
+		t.Errorf("got %v -> %v, wanted 2024-01 -> 2024-02", got[2].From, got[2].To)
+	}
+
+	if changes, err := Diff(nil, nil); err != nil || len(changes) != 0 {
+		t.Errorf("got %v %v, wanted no difference", changes, err)
+	}
+}
+
+func TestDiffJSONNumbers(t *testing.T) {
+	tests := []struct {
+		a       string
+		b       string
+		changed bool
+	}{
+		{a: `{"n":12345678901234567890}`, b: `{"n":12345678901234567891}`, changed: true},
+		{a: `{"n":1e400}`, b: `{"n":1e400}`, changed: false},
+		{a: `{"n":1.50}`, b: `{"n":15e-1}`, changed: false},
+		{a: `{"n":0}`, b: `{"n":-0.0}`, changed: false},
+		{a: `{"n":1}`, b: `{"n":"1"}`, changed: true},
+	}
+
+	for _, tt := range tests {
+		got, err := DiffJSON([]byte(tt.a), []byte(tt.b))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if changed := len(got) > 0; changed != tt.changed {
+			t.Errorf("%s -> %s: got %v, wanted changed %v", tt.a, tt.b, got, tt.changed)
+		}
+	}
+
+	if _, err := MergePatch([]byte(`{"a":1} x`), []byte(`{}`)); err == nil {
+		t.Errorf("got nil, wanted error for trailing data")
+	}
+}
@@ -0,0 +1,582 @@
+/*
+ * Copyright (c) 2022 The Mof Authors
+ */
+
+package pio
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mofcloud/mof-common/time"
+)
+
+// ****** Nested map path ******
+//
+// Path is either dotted like tags.env or items.0.name, or JSON Pointer like /tags/env.
+// Numeric segment is index of array. Values are usually decoded by encoding/json,
+// which are map[string]interface{}, []interface{} and scalars.
+
+var (
+	// ErrPathNotFound returned if any segment of path does not exist
+	ErrPathNotFound = errors.New("path not found")
+	// ErrTypeMismatch returned by typed getters if value cannot be converted
+	ErrTypeMismatch = errors.New("type mismatch")
+)
+
+// PathError returned when value at path is missing or cannot be used
+//
+// Path: the path given by caller
+// Err: ErrPathNotFound or ErrTypeMismatch
+// Detail: what is wrong, optional
+//
+// Both errors.Is(err, ErrPathNotFound) and errors.As(err, &pathErr) are supported.
+type PathError struct {
+	Path   string
+	Err    error
+	Detail string
+}
+
+func (e *PathError) Error() string {
+	if len(e.Detail) > 0 {
+		return fmt.Sprintf("%v: %s, %s", e.Err, e.Path, e.Detail)
+	}
+
+	return fmt.Sprintf("%v: %s", e.Err, e.Path)
+}
+
+// Unwrap returns ErrPathNotFound or ErrTypeMismatch
+func (e *PathError) Unwrap() error {
+	return e.Err
+}
+
+// ParsePath split dotted path or JSON Pointer into segments, empty path is the whole map
+func ParsePath(path string) ([]string, error) {
+	if path == "" || strings.HasPrefix(path, "/") {
+		return ParseJSONPointer(path)
+	}
+
+	res := strings.Split(path, ".")
+	for i := range res {
+		if res[i] == "" {
+			return nil, errors.New(fmt.Sprintf("invalid path: %q, empty segment", path))
+		}
+	}
+
+	return res, nil
+}
+
+// GetPath returns value at path, ErrPathNotFound is wrapped if it does not exist
+//
+// Maps with string keys and slices of any type are supported on the way.
+func GetPath(m map[string]interface{}, path string) (interface{}, error) {
+	tokens, err := ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return getPathValue(m, tokens, path)
+}
+
+// SetPath set value at path, missing objects on the way are created
+//
+// Segment of existing array must be an index in range, or "-" to append.
+// Maps with string keys and slices of any type are supported on the way,
+// ErrTypeMismatch is wrapped if value cannot be stored in them.
+func SetPath(m map[string]interface{}, path string, value interface{}) error {
+	tokens, err := ParsePath(path)
+	if err != nil {
+		return err
+	}
+
+	if len(tokens) < 1 {
+		return errors.New(fmt.Sprintf("invalid path: %q, cannot set whole map", path))
+	}
+
+	_, err = setPathValue(m, tokens, value, path)
+	return err
+}
+
+// DeletePath remove value at path, ErrPathNotFound is wrapped if it does not exist
+//
+// Maps with string keys and slices of any type are supported on the way.
+func DeletePath(m map[string]interface{}, path string) error {
+	tokens, err := ParsePath(path)
+	if err != nil {
+		return err
+	}
+
+	if len(tokens) < 1 {
+		return errors.New(fmt.Sprintf("invalid path: %q, cannot delete whole map", path))
+	}
+
+	_, err = deletePathValue(m, tokens, path)
+	return err
+}
+
+// FlattenMap convert nested maps and arrays into single level map with dotted keys
+//
+// {"a": {"b": 1}, "c": [2]} => {"a.b": 1, "c.0": 2}, empty maps and arrays are kept as values.
+func FlattenMap(m map[string]interface{}) map[string]interface{} {
+	res := make(map[string]interface{})
+	flattenPathValue("", m, res)
+	return res
+}
+
+// UnflattenMap convert dotted keys back into nested maps, see FlattenMap
+//
+// Maps whose keys are exactly 0 to n-1 are converted to arrays.
+func UnflattenMap(m map[string]interface{}) (map[string]interface{}, error) {
+	res := make(map[string]interface{})
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		tokens := strings.Split(k, ".")
+		node := res
+
+		for i, tok := range tokens[:len(tokens)-1] {
+			// keys are sorted, so value of prefix is always stored before
+			if prefix := strings.Join(tokens[:i+1], "."); hasKey(m, prefix) {
+				return nil, errors.New(fmt.Sprintf("conflicting keys: %q and %q", prefix, k))
+			}
+
+			child, ok := node[tok]
+			if !ok {
+				child = make(map[string]interface{})
+				node[tok] = child
+			}
+			node = child.(map[string]interface{})
+		}
+
+		node[tokens[len(tokens)-1]] = copyJSON(m[k])
+	}
+
+	// the result is always a map, only its children are converted to arrays
+	for k := range res {
+		res[k] = arraysFromMaps(res[k])
+	}
+
+	return res, nil
+}
+
+// GetString returns string at path
+func GetString(m map[string]interface{}, path string) (string, error) {
+	v, err := GetPath(m, path)
+	if err != nil {
+		return "", err
+	}
+
+	switch t := v.(type) {
+	case string:
+		return t, nil
+	case json.Number:
+		return t.String(), nil
+	}
+
+	return "", typeMismatch(path, "string", v)
+}
+
+// GetFloat returns number at path, numeric string is accepted
+func GetFloat(m map[string]interface{}, path string) (float64, error) {
+	v, err := GetPath(m, path)
+	if err != nil {
+		return 0, err
+	}
+
+	switch t := v.(type) {
+	case json.Number:
+		return parsePathFloat(path, t.String())
+	case string:
+		return parsePathFloat(path, t)
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), nil
+	}
+
+	return 0, typeMismatch(path, "number", v)
+}
+
+// GetInt returns integer at path, numeric string and number without fraction are accepted
+func GetInt(m map[string]interface{}, path string) (int64, error) {
+	v, err := GetPath(m, path)
+	if err != nil {
+		return 0, err
+	}
+
+	switch t := v.(type) {
+	case json.Number:
+		return parsePathInt(path, t.String())
+	case string:
+		return parsePathInt(path, t)
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if rv.Uint() <= math.MaxInt64 {
+			return int64(rv.Uint()), nil
+		}
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		if f == math.Trunc(f) && f >= math.MinInt64 && f < math.MaxInt64 {
+			return int64(f), nil
+		}
+	}
+
+	return 0, typeMismatch(path, "integer", v)
+}
+
+// GetBool returns bool at path, strings like "true" and "false" are accepted
+func GetBool(m map[string]interface{}, path string) (bool, error) {
+	v, err := GetPath(m, path)
+	if err != nil {
+		return false, err
+	}
+
+	switch t := v.(type) {
+	case bool:
+		return t, nil
+	case string:
+		if res, err := strconv.ParseBool(strings.TrimSpace(t)); err == nil {
+			return res, nil
+		}
+	}
+
+	return false, typeMismatch(path, "bool", v)
+}
+
+// GetTime returns time at path, string is parsed by ptime.StringToTime and number is epoch in any unit
+func GetTime(m map[string]interface{}, path string) (time.Time, error) {
+	v, err := GetPath(m, path)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	switch t := v.(type) {
+	case time.Time:
+		return t, nil
+	case *time.Time:
+		if t != nil {
+			return *t, nil
+		}
+	case string:
+		res, err := ptime.StringToTime(strings.TrimSpace(t))
+		if err != nil {
+			return time.Time{}, &PathError{Path: path, Err: ErrTypeMismatch, Detail: err.Error()}
+		}
+		return res, nil
+	case json.Number:
+		return parsePathEpoch(path, t.String())
+	case float64:
+		return parsePathEpoch(path, strconv.FormatFloat(t, 'f', -1, 64))
+	case int64:
+		return parsePathEpoch(path, strconv.FormatInt(t, 10))
+	case int:
+		return parsePathEpoch(path, strconv.Itoa(t))
+	}
+
+	return time.Time{}, typeMismatch(path, "time", v)
+}
+
+func getPathValue(doc interface{}, tokens []string, path string) (interface{}, error) {
+	curr := doc
+
+	for i := range tokens {
+		v, ok := childOf(curr, tokens[i])
+		if !ok {
+			return nil, &PathError{Path: path, Err: ErrPathNotFound}
+		}
+		curr = v
+	}
+
+	return curr, nil
+}
+
+func setPathValue(node interface{}, tokens []string, value interface{}, path string) (interface{}, error) {
+	if len(tokens) < 1 {
+		return value, nil
+	}
+
+	child, _ := childOf(node, tokens[0])
+
+	res, err := setPathValue(child, tokens[1:], value, path)
+	if err != nil {
+		return nil, err
+	}
+
+	return setChild(node, tokens[0], res, path)
+}
+
+func deletePathValue(node interface{}, tokens []string, path string) (interface{}, error) {
+	if len(tokens) == 1 {
+		return removeChild(node, tokens[0], path)
+	}
+
+	child, ok := childOf(node, tokens[0])
+	if !ok {
+		return nil, &PathError{Path: path, Err: ErrPathNotFound}
+	}
+
+	res, err := deletePathValue(child, tokens[1:], path)
+	if err != nil {
+		return nil, err
+	}
+
+	return setChild(node, tokens[0], res, path)
+}
+
+// childOf returns child at token, typed containers like map[string]string or []string are supported
+func childOf(node interface{}, token string) (interface{}, bool) {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		v, ok := n[token]
+		return v, ok
+	case []interface{}:
+		idx, err := arrayIndex(token, len(n), false)
+		if err != nil {
+			return nil, false
+		}
+		return n[idx], true
+	}
+
+	rv := reflect.ValueOf(node)
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return nil, false
+		}
+
+		v := rv.MapIndex(reflect.ValueOf(token).Convert(rv.Type().Key()))
+		if !v.IsValid() {
+			return nil, false
+		}
+		return v.Interface(), true
+	case reflect.Slice, reflect.Array:
+		idx, err := arrayIndex(token, rv.Len(), false)
+		if err != nil {
+			return nil, false
+		}
+		return rv.Index(idx).Interface(), true
+	}
+
+	return nil, false
+}
+
+// setChild store child at token and returns updated container, "-" appends to array, nil becomes object
+func setChild(node interface{}, token string, child interface{}, path string) (interface{}, error) {
+	switch n := node.(type) {
+	case nil:
+		return map[string]interface{}{token: child}, nil
+	case map[string]interface{}:
+		if n == nil {
+			n = make(map[string]interface{})
+		}
+		n[token] = child
+		return n, nil
+	case []interface{}:
+		idx, err := arrayIndex(token, len(n), true)
+		if err != nil {
+			return nil, &PathError{Path: path, Err: ErrPathNotFound, Detail: err.Error()}
+		}
+
+		if idx == len(n) {
+			return append(n, child), nil
+		}
+		n[idx] = child
+		return n, nil
+	}
+
+	rv := reflect.ValueOf(node)
+	switch {
+	case rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String:
+		v, err := pathValueOf(child, rv.Type().Elem(), path)
+		if err != nil {
+			return nil, err
+		}
+
+		if rv.IsNil() {
+			rv = reflect.MakeMap(rv.Type())
+		}
+		rv.SetMapIndex(reflect.ValueOf(token).Convert(rv.Type().Key()), v)
+		return rv.Interface(), nil
+	case rv.Kind() == reflect.Slice:
+		idx, err := arrayIndex(token, rv.Len(), true)
+		if err != nil {
+			return nil, &PathError{Path: path, Err: ErrPathNotFound, Detail: err.Error()}
+		}
+
+		v, err := pathValueOf(child, rv.Type().Elem(), path)
+		if err != nil {
+			return nil, err
+		}
+
+		if idx == rv.Len() {
+			return reflect.Append(rv, v).Interface(), nil
+		}
+		rv.Index(idx).Set(v)
+		return rv.Interface(), nil
+	}
+
+	return nil, &PathError{Path: path, Err: ErrTypeMismatch, Detail: fmt.Sprintf("parent of %q is %T, not object or array", token, node)}
+}
+
+// removeChild remove child at token and returns updated container
+func removeChild(node interface{}, token string, path string) (interface{}, error) {
+	if _, ok := childOf(node, token); !ok {
+		return nil, &PathError{Path: path, Err: ErrPathNotFound}
+	}
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		delete(n, token)
+		return n, nil
+	case []interface{}:
+		idx, _ := arrayIndex(token, len(n), false)
+		return append(n[:idx], n[idx+1:]...), nil
+	}
+
+	rv := reflect.ValueOf(node)
+	switch rv.Kind() {
+	case reflect.Map:
+		rv.SetMapIndex(reflect.ValueOf(token).Convert(rv.Type().Key()), reflect.Value{})
+		return node, nil
+	case reflect.Slice:
+		idx, _ := arrayIndex(token, rv.Len(), false)
+		return reflect.AppendSlice(rv.Slice(0, idx), rv.Slice(idx+1, rv.Len())).Interface(), nil
+	}
+
+	return nil, &PathError{Path: path, Err: ErrTypeMismatch, Detail: fmt.Sprintf("parent of %q is %T, not object or array", token, node)}
+}
+
+// pathValueOf convert v to value which can be stored in container whose elements are of type t
+func pathValueOf(v interface{}, t reflect.Type, path string) (reflect.Value, error) {
+	if v == nil {
+		switch t.Kind() {
+		case reflect.Interface, reflect.Pointer, reflect.Map, reflect.Slice:
+			return reflect.Zero(t), nil
+		}
+	} else if rv := reflect.ValueOf(v); rv.Type().AssignableTo(t) {
+		return rv, nil
+	}
+
+	return reflect.Value{}, &PathError{Path: path, Err: ErrTypeMismatch, Detail: fmt.Sprintf("%T cannot be stored as %s", v, t)}
+}
+
+func hasKey(m map[string]interface{}, k string) bool {
+	_, ok := m[k]
+	return ok
+}
+
+func flattenPathValue(prefix string, v interface{}, res map[string]interface{}) {
+	join := func(k string) string {
+		if prefix == "" {
+			return k
+		}
+		return prefix + "." + k
+	}
+
+	switch node := v.(type) {
+	case map[string]interface{}:
+		if len(node) > 0 {
+			for k := range node {
+				flattenPathValue(join(k), node[k], res)
+			}
+			return
+		}
+	case []interface{}:
+		if len(node) > 0 {
+			for i := range node {
+				flattenPathValue(join(strconv.Itoa(i)), node[i], res)
+			}
+			return
+		}
+	}
+
+	if prefix != "" {
+		res[prefix] = v
+	}
+}
+
+// arraysFromMaps convert maps whose keys are exactly 0 to n-1 into arrays, recursively
+func arraysFromMaps(v interface{}) interface{} {
+	node, ok := v.(map[string]interface{})
+	if !ok {
+		return v
+	}
+
+	for k := range node {
+		node[k] = arraysFromMaps(node[k])
+	}
+
+	if len(node) < 1 {
+		return node
+	}
+
+	res := make([]interface{}, len(node))
+	for k := range node {
+		idx, err := arrayIndex(k, len(node), false)
+		if err != nil {
+			return node
+		}
+		res[idx] = node[k]
+	}
+
+	return res
+}
+
+func typeMismatch(path, wanted string, v interface{}) error {
+	return &PathError{Path: path, Err: ErrTypeMismatch, Detail: fmt.Sprintf("%T is not %s", v, wanted)}
+}
+
+func parsePathFloat(path, str string) (float64, error) {
+	res, err := strconv.ParseFloat(strings.TrimSpace(str), 64)
+	if err != nil || math.IsNaN(res) || math.IsInf(res, 0) {
+		return 0, &PathError{Path: path, Err: ErrTypeMismatch, Detail: fmt.Sprintf("%q is not number", str)}
+	}
+
+	return res, nil
+}
+
+func parsePathInt(path, str string) (int64, error) {
+	str = strings.TrimSpace(str)
+	if res, err := strconv.ParseInt(str, 10, 64); err == nil {
+		return res, nil
+	}
+
+	f, err := strconv.ParseFloat(str, 64)
+	if err != nil || f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, &PathError{Path: path, Err: ErrTypeMismatch, Detail: fmt.Sprintf("%q is not integer", str)}
+	}
+
+	return int64(f), nil
+}
+
+func parsePathEpoch(path, str string) (time.Time, error) {
+	res, err := ptime.ParseEpoch(str, ptime.EpochAuto)
+	if err != nil {
+		return time.Time{}, &PathError{Path: path, Err: ErrTypeMismatch, Detail: err.Error()}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,274 @@
+package pio
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testMetadata(t *testing.T) map[string]interface{} {
+	raw := `{
+		"tags": {"env": "prod", "a/b": "slash"},
+		"items": [{"name": "vm-1", "cost": "12.50", "count": 3}, {"name": "vm-2", "cost": 1.5e1, "count": "4"}],
+		"active": true,
+		"created": "2024-01-02",
+		"updated": 1704153600
+	}`
+
+	dec := json.NewDecoder(strings.NewReader(raw))
+	dec.UseNumber()
+
+	res := make(map[string]interface{})
+	if err := dec.Decode(&res); err != nil {
+		t.Fatal(err)
+	}
+
+	res["labels"] = map[string]string{"team": "finops"}
+	return res
+}
+
+func TestParsePath(t *testing.T) {
+	tests := map[string][]string{
+		"":            {},
+		"a.b.0":       {"a", "b", "0"},
+		"/a/b~1c/m~0": {"a", "b/c", "m~"},
+	}
+
+	for in, wanted := range tests {
+		got, err := ParsePath(in)
+		if err != nil || !reflect.DeepEqual(got, wanted) {
+			t.Errorf("got %v %v, wanted %v", got, err, wanted)
+		}
+	}
+
+	for _, in := range []string{"a..b", "/a~2"} {
+		if _, err := ParsePath(in); err == nil {
+			t.Errorf("got nil, wanted error for %q", in)
+		}
+	}
+}
+
+func TestGetPathAndTypedGetters(t *testing.T) {
+	m := testMetadata(t)
+
+	if got, err := GetString(m, "tags.env"); err != nil || got != "prod" {
+		t.Errorf("got %q %v, wanted %q", got, err, "prod")
+	}
+
+	if got, err := GetString(m, "/tags/a~1b"); err != nil || got != "slash" {
+		t.Errorf("got %q %v, wanted %q", got, err, "slash")
+	}
+
+	if got, err := GetString(m, "labels.team"); err != nil || got != "finops" {
+		t.Errorf("got %q %v, wanted %q", got, err, "finops")
+	}
+
+	if got, err := GetFloat(m, "items.0.cost"); err != nil || got != 12.5 {
+		t.Errorf("got %v %v, wanted %v", got, err, 12.5)
+	}
+
+	if got, err := GetFloat(m, "items.1.cost"); err != nil || got != 15 {
+		t.Errorf("got %v %v, wanted %v", got, err, 15)
+	}
+
+	if got, err := GetInt(m, "items.1.count"); err != nil || got != 4 {
+		t.Errorf("got %v %v, wanted %v", got, err, 4)
+	}
+
+	if got, err := GetBool(m, "active"); err != nil || !got {
+		t.Errorf("got %v %v, wanted true", got, err)
+	}
+
+	if got, err := GetTime(m, "created"); err != nil || got.Format("2006-01-02") != "2024-01-02" {
+		t.Errorf("got %v %v, wanted 2024-01-02", got, err)
+	}
+
+	if got, err := GetTime(m, "updated"); err != nil || !got.Equal(time.Unix(1704153600, 0)) {
+		t.Errorf("got %v %v, wanted %v", got, err, time.Unix(1704153600, 0))
+	}
+
+	for _, path := range []string{"tags.missing", "items.2.name", "items.01", "active.x"} {
+		if _, err := GetPath(m, path); !errors.Is(err, ErrPathNotFound) {
+			t.Errorf("got %v, wanted ErrPathNotFound for %q", err, path)
+		}
+	}
+
+	if _, err := GetInt(m, "tags.env"); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("got %v, wanted ErrTypeMismatch", err)
+	}
+
+	if _, err := GetString(m, "items"); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("got %v, wanted ErrTypeMismatch", err)
+	}
+}
+
+func TestSetAndDeletePath(t *testing.T) {
+	m := testMetadata(t)
+
+	if err := SetPath(m, "a.b.c", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SetPath(m, "items.-", "vm-3"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SetPath(m, "/items/0/name", "vm-0"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, _ := GetInt(m, "a.b.c"); got != 1 {
+		t.Errorf("got %v, wanted 1", got)
+	}
+
+	if got, _ := GetString(m, "items.2"); got != "vm-3" {
+		t.Errorf("got %q, wanted %q", got, "vm-3")
+	}
+
+	if got, _ := GetString(m, "items.0.name"); got != "vm-0" {
+		t.Errorf("got %q, wanted %q", got, "vm-0")
+	}
+
+	if err := SetPath(m, "active.x", 1); err == nil {
+		t.Errorf("got nil, wanted error setting into bool")
+	}
+
+	if err := SetPath(m, "items.5", 1); err == nil {
+		t.Errorf("got nil, wanted error for index out of range")
+	}
+
+	if err := DeletePath(m, "items.1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, _ := GetString(m, "items.1"); got != "vm-3" {
+		t.Errorf("got %q, wanted %q", got, "vm-3")
+	}
+
+	if err := DeletePath(m, "tags.env"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := DeletePath(m, "tags.env"); !errors.Is(err, ErrPathNotFound) {
+		t.Errorf("got %v, wanted ErrPathNotFound", err)
+	}
+}
+
+func TestSetAndDeletePathTypedContainers(t *testing.T) {
+	m := map[string]interface{}{
+		"labels": map[string]string{"team": "finops", "env": "prod"},
+		"zones":  []string{"a", "b", "c"},
+	}
+
+	if err := SetPath(m, "labels.owner", "ops"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SetPath(m, "zones.-", "d"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SetPath(m, "labels.count", 1); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("got %v, wanted ErrTypeMismatch", err)
+	}
+
+	if err := DeletePath(m, "labels.env"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := DeletePath(m, "zones.0"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := DeletePath(m, "labels.env"); !errors.Is(err, ErrPathNotFound) {
+		t.Errorf("got %v, wanted ErrPathNotFound", err)
+	}
+
+	wanted := map[string]interface{}{
+		"labels": map[string]string{"team": "finops", "owner": "ops"},
+		"zones":  []string{"b", "c", "d"},
+	}
+	if !reflect.DeepEqual(m, wanted) {
+		t.Errorf("got %v, wanted %v", m, wanted)
+	}
+
+	var pathErr *PathError
+	if _, err := GetPath(m, "labels.missing"); !errors.As(err, &pathErr) || pathErr.Path != "labels.missing" {
+		t.Errorf("got %v, wanted PathError", err)
+	}
+}
+
+func TestFlattenAndUnflattenMap(t *testing.T) {
+	m := map[string]interface{}{
+		"a":     map[string]interface{}{"b": 1, "c": []interface{}{"x", map[string]interface{}{"d": true}}},
+		"empty": map[string]interface{}{},
+		"list":  []interface{}{},
+		"e":     "f",
+	}
+
+	flat := FlattenMap(m)
+	wanted := map[string]interface{}{
+		"a.b":     1,
+		"a.c.0":   "x",
+		"a.c.1.d": true,
+		"empty":   map[string]interface{}{},
+		"list":    []interface{}{},
+		"e":       "f",
+	}
+	if !reflect.DeepEqual(flat, wanted) {
+		t.Errorf("got %v, wanted %v", flat, wanted)
+	}
+
+	back, err := UnflattenMap(flat)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(back, m) {
+		t.Errorf("got %v, wanted %v", back, m)
+	}
+
+	if _, err := UnflattenMap(map[string]interface{}{"a": 1, "a.b": 2}); err == nil {
+		t.Errorf("got nil, wanted error for conflicting keys")
+	}
+}
+
+func TestUnflattenMapRoot(t *testing.T) {
+	got, err := UnflattenMap(map[string]interface{}{"0": "a", "1": "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if wanted := map[string]interface{}{"0": "a", "1": "b"}; !reflect.DeepEqual(got, wanted) {
+		t.Errorf("got %v, wanted %v", got, wanted)
+	}
+
+	m := map[string]interface{}{"0": map[string]interface{}{"x": 1}}
+	if got, err := UnflattenMap(FlattenMap(m)); err != nil || !reflect.DeepEqual(got, m) {
+		t.Errorf("got %v %v, wanted %v", got, err, m)
+	}
+}
+
+func TestUnflattenMapKeepsInput(t *testing.T) {
+	in := map[string]interface{}{"a.b": map[string]interface{}{"0": "x"}}
+
+	got, err := UnflattenMap(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := in["a.b"].(map[string]interface{}); !ok {
+		t.Errorf("got %v, wanted input untouched", in)
+	}
+
+	if err := SetPath(got, "a.b.0", "y"); err != nil {
+		t.Fatal(err)
+	}
+
+	if in["a.b"].(map[string]interface{})["0"] != "x" {
+		t.Errorf("got %v, wanted input untouched", in)
+	}
+}
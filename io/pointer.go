@@ -0,0 +1,258 @@
+/*
+ * Copyright (c) 2022 The Mof Authors
+ */
+
+package pio
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ****** JSON Pointer ******
+//
+// JSON Pointer follows RFC 6901, like /a/b/0, ~1 and ~0 stand for / and ~ in key.
+// Documents are decoded by encoding/json into map[string]interface{},
+// []interface{} and scalars.
+
+// ParseJSONPointer split JSON Pointer into unescaped tokens, empty pointer is the whole document
+func ParseJSONPointer(ptr string) ([]string, error) {
+	if ptr == "" {
+		return []string{}, nil
+	}
+
+	if !strings.HasPrefix(ptr, "/") {
+		return nil, errors.New(fmt.Sprintf("invalid JSON pointer: %q, should start with /", ptr))
+	}
+
+	res := strings.Split(ptr[1:], "/")
+	for i := range res {
+		if strings.Contains(strings.NewReplacer("~0", "", "~1", "").Replace(res[i]), "~") {
+			return nil, errors.New(fmt.Sprintf("invalid JSON pointer: %q, ~ should be escaped as ~0", ptr))
+		}
+
+		res[i] = strings.ReplaceAll(strings.ReplaceAll(res[i], "~1", "/"), "~0", "~")
+	}
+
+	return res, nil
+}
+
+// FormatJSONPointer join tokens into JSON Pointer, / and ~ in tokens are escaped
+func FormatJSONPointer(tokens []string) string {
+	b := strings.Builder{}
+
+	for i := range tokens {
+		b.WriteByte('/')
+		b.WriteString(strings.ReplaceAll(strings.ReplaceAll(tokens[i], "~", "~0"), "/", "~1"))
+	}
+
+	return b.String()
+}
+
+// arrayIndex parse token as index of array with length n, "-" is n if allowed
+func arrayIndex(token string, n int, allowEnd bool) (int, error) {
+	if token == "-" && allowEnd {
+		return n, nil
+	}
+
+	if token == "" || (len(token) > 1 && token[0] == '0') || !isDigits(token) {
+		return 0, errors.New(fmt.Sprintf("invalid array index: %q", token))
+	}
+
+	i, err := strconv.Atoi(token)
+	if err != nil {
+		return 0, errors.New(fmt.Sprintf("invalid array index: %q", token))
+	}
+
+	last := n - 1
+	if allowEnd {
+		last = n
+	}
+
+	if i > last {
+		return 0, errors.New(fmt.Sprintf("array index out of range: %d", i))
+	}
+
+	return i, nil
+}
+
+func isDigits(str string) bool {
+	for i := range str {
+		if str[i] < '0' || str[i] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// lookupJSON returns value at tokens
+func lookupJSON(doc interface{}, tokens []string) (interface{}, error) {
+	curr := doc
+
+	for i := range tokens {
+		switch node := curr.(type) {
+		case map[string]interface{}:
+			v, ok := node[tokens[i]]
+			if !ok {
+				return nil, errors.New(fmt.Sprintf("path not found: %s", FormatJSONPointer(tokens[:i+1])))
+			}
+			curr = v
+		case []interface{}:
+			idx, err := arrayIndex(tokens[i], len(node), false)
+			if err != nil {
+				return nil, errors.New(fmt.Sprintf("path not found: %s, %v", FormatJSONPointer(tokens[:i+1]), err))
+			}
+			curr = node[idx]
+		default:
+			return nil, errors.New(fmt.Sprintf("path not found: %s", FormatJSONPointer(tokens[:i+1])))
+		}
+	}
+
+	return curr, nil
+}
+
+// updateJSON apply fn to parent of last token, containers on the way are updated with results
+func updateJSON(doc interface{}, tokens []string, fn func(parent interface{}, last string) (interface{}, error)) (interface{}, error) {
+	return updateJSONAt(doc, tokens, 0, fn)
+}
+
+// updateJSONAt updateJSON from tokens[depth], tokens is the full path so that errors name it from the root
+func updateJSONAt(doc interface{}, tokens []string, depth int, fn func(parent interface{}, last string) (interface{}, error)) (interface{}, error) {
+	if depth == len(tokens)-1 {
+		return fn(doc, tokens[depth])
+	}
+
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		child, ok := node[tokens[depth]]
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("path not found: %s", FormatJSONPointer(tokens[:depth+1])))
+		}
+
+		res, err := updateJSONAt(child, tokens, depth+1, fn)
+		if err != nil {
+			return nil, err
+		}
+
+		node[tokens[depth]] = res
+		return node, nil
+	case []interface{}:
+		idx, err := arrayIndex(tokens[depth], len(node), false)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("path not found: %s, %v", FormatJSONPointer(tokens[:depth+1]), err))
+		}
+
+		res, err := updateJSONAt(node[idx], tokens, depth+1, fn)
+		if err != nil {
+			return nil, err
+		}
+
+		node[idx] = res
+		return node, nil
+	}
+
+	return nil, errors.New(fmt.Sprintf("path not found: %s, parent is not object or array", FormatJSONPointer(tokens[:depth+1])))
+}
+
+// copyJSON deep copy decoded JSON value
+func copyJSON(v interface{}) interface{} {
+	switch node := v.(type) {
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(node))
+		for k := range node {
+			res[k] = copyJSON(node[k])
+		}
+		return res
+	case []interface{}:
+		res := make([]interface{}, len(node))
+		for i := range node {
+			res[i] = copyJSON(node[i])
+		}
+		return res
+	}
+
+	return v
+}
+
+// equalJSON compare decoded JSON values, numbers are compared by exact value, so 1.0 equals 1
+func equalJSON(a, b interface{}) bool {
+	switch na := a.(type) {
+	case map[string]interface{}:
+		nb, ok := b.(map[string]interface{})
+		if !ok || len(na) != len(nb) {
+			return false
+		}
+
+		for k := range na {
+			vb, ok := nb[k]
+			if !ok || !equalJSON(na[k], vb) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		nb, ok := b.([]interface{})
+		if !ok || len(na) != len(nb) {
+			return false
+		}
+
+		for i := range na {
+			if !equalJSON(na[i], nb[i]) {
+				return false
+			}
+		}
+		return true
+	case json.Number, float64:
+		switch b.(type) {
+		case json.Number, float64:
+			return normalizeNumber(a) == normalizeNumber(b)
+		}
+		return false
+	}
+
+	return a == b
+}
+
+// normalizeNumber returns exact form of number as sign, significant digits and exponent
+//
+// 1.50, 15e-1 and 0.15E1 => 15e-1, text which is not a decimal is returned as it is
+func normalizeNumber(v interface{}) string {
+	text := fmt.Sprint(v)
+	if f, ok := v.(float64); ok {
+		text = strconv.FormatFloat(f, 'g', -1, 64)
+	}
+
+	m := decimalRegexp.FindStringSubmatch(text)
+	if m == nil || m[2]+m[3] == "" {
+		return text
+	}
+
+	exp := 0
+	if m[4] != "" {
+		v, err := strconv.Atoi(m[4])
+		if err != nil {
+			return text
+		}
+		exp = v
+	}
+
+	digits := strings.TrimLeft(m[2]+m[3], "0")
+	if digits == "" {
+		return "0"
+	}
+
+	exp -= len(m[3])
+	trimmed := strings.TrimRight(digits, "0")
+	exp += len(digits) - len(trimmed)
+
+	sign := m[1]
+	if sign == "+" {
+		sign = ""
+	}
+
+	return sign + trimmed + "e" + strconv.Itoa(exp)
+}
@@ -0,0 +1,424 @@
+/*
+ * Copyright (c) 2022 The Mof Authors
+ */
+
+package pio
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"unsafe"
+)
+
+// RedactedValue replaces redacted secrets
+const RedactedValue = "******"
+
+// secretTag fields tagged with mof:"secret" are redacted
+const secretTag = "secret"
+
+var (
+	// sensitiveKeyParts key contains any of them is sensitive, key is lower cased without _ and -
+	sensitiveKeyParts = []string{"secret", "password", "passwd", "privatekey", "credential"}
+	// sensitiveKeys key equals to any of them is sensitive, NextToken used by paging is not
+	sensitiveKeys = map[string]bool{
+		"token":         true,
+		"accesstoken":   true,
+		"refreshtoken":  true,
+		"sessiontoken":  true,
+		"securitytoken": true,
+		"idtoken":       true,
+		"authtoken":     true,
+		"apikey":        true,
+	}
+
+	sensitivePatterns = []*regexp.Regexp{
+		// PEM private key, like private_key of GCP service account JSON
+		regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`),
+		// AWS access key ID
+		regexp.MustCompile(`\b(AKIA|ASIA|AIDA|AROA|AGPA|ANPA|ANVA|AIPA)[A-Z0-9]{16}\b`),
+		// Alibaba Cloud AccessKey ID
+		regexp.MustCompile(`\bLTAI[A-Za-z0-9]{12,20}\b`),
+	}
+)
+
+// RedactedJSON marshal v as compact JSON with secrets redacted
+//
+// Fields tagged with mof:"secret" are redacted first, see RedactStruct,
+// then the JSON is redacted with RedactJSONBytes.
+func RedactedJSON(v interface{}) (string, error) {
+	b, err := json.Marshal(RedactStruct(v))
+	if err != nil {
+		return "", err
+	}
+
+	b, err = RedactJSONBytes(b)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// RedactJSONBytes redact secrets in JSON document, order of keys is kept
+//
+// Values of sensitive keys like password, secret, access_key_secret, private_key or token
+// are replaced with RedactedValue. AWS access key IDs, Alibaba AccessKey IDs and PEM
+// private keys are replaced in any string, including JSON documents embedded in string.
+func RedactJSONBytes(raw []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	if err := redactJSONValue(dec, buf, tok); err != nil {
+		return nil, err
+	}
+
+	if err := expectJSONEnd(dec); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// RedactStruct returns a deep copy of in, fields tagged with mof:"secret" are redacted
+//
+// String fields are replaced with RedactedValue, other fields are set to zero value.
+// Types implementing json.Marshaler or encoding.TextMarshaler are copied as they are.
+func RedactStruct(in interface{}) interface{} {
+	if in == nil {
+		return nil
+	}
+
+	r := &redactor{seen: make(map[redactKey]reflect.Value)}
+	return r.copy(reflect.ValueOf(in)).Interface()
+}
+
+// redactValue returns a deep copy of in with secrets redacted like RedactedJSON, types are kept
+//
+// Besides fields tagged with mof:"secret", fields and map entries with sensitive keys are
+// redacted, and secrets in strings are replaced, see RedactJSONBytes.
+func redactValue(in interface{}) interface{} {
+	if in == nil {
+		return nil
+	}
+
+	r := &redactor{seen: make(map[redactKey]reflect.Value), keys: true}
+	return r.copy(reflect.ValueOf(in)).Interface()
+}
+
+func redactJSONValue(dec *json.Decoder, buf *bytes.Buffer, tok json.Token) error {
+	switch t := tok.(type) {
+	case json.Delim:
+		if t == '{' {
+			buf.WriteByte('{')
+			for i := 0; dec.More(); i++ {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return err
+				}
+
+				key, _ := keyTok.(string)
+				if i > 0 {
+					buf.WriteByte(',')
+				}
+				writeJSONString(buf, key)
+				buf.WriteByte(':')
+
+				valTok, err := dec.Token()
+				if err != nil {
+					return err
+				}
+
+				if isSensitiveKey(key) {
+					err = maskJSONValue(dec, buf, valTok)
+				} else {
+					err = redactJSONValue(dec, buf, valTok)
+				}
+				if err != nil {
+					return err
+				}
+			}
+			buf.WriteByte('}')
+		} else {
+			buf.WriteByte('[')
+			for i := 0; dec.More(); i++ {
+				valTok, err := dec.Token()
+				if err != nil {
+					return err
+				}
+
+				if i > 0 {
+					buf.WriteByte(',')
+				}
+				if err := redactJSONValue(dec, buf, valTok); err != nil {
+					return err
+				}
+			}
+			buf.WriteByte(']')
+		}
+
+		// closing } or ]
+		_, err := dec.Token()
+		return err
+	case string:
+		writeJSONString(buf, redactString(t))
+	case json.Number:
+		buf.WriteString(t.String())
+	case bool:
+		fmt.Fprint(buf, t)
+	case nil:
+		buf.WriteString("null")
+	}
+
+	return nil
+}
+
+// maskJSONValue replace value with RedactedValue, nested objects and arrays are dropped as a whole
+func maskJSONValue(dec *json.Decoder, buf *bytes.Buffer, tok json.Token) error {
+	if tok == nil {
+		buf.WriteString("null")
+		return nil
+	}
+
+	if _, ok := tok.(json.Delim); ok {
+		for depth := 1; depth > 0; {
+			next, err := dec.Token()
+			if err != nil {
+				return err
+			}
+
+			if d, ok := next.(json.Delim); ok {
+				if d == '{' || d == '[' {
+					depth++
+				} else {
+					depth--
+				}
+			}
+		}
+	}
+
+	writeJSONString(buf, RedactedValue)
+	return nil
+}
+
+// redactString replace secrets in str, JSON object in str is redacted as well
+func redactString(str string) string {
+	if trimmed := strings.TrimSpace(str); strings.HasPrefix(trimmed, "{") && json.Valid([]byte(trimmed)) {
+		if b, err := RedactJSONBytes([]byte(trimmed)); err == nil {
+			return string(b)
+		}
+	}
+
+	for i := range sensitivePatterns {
+		str = sensitivePatterns[i].ReplaceAllString(str, RedactedValue)
+	}
+
+	return str
+}
+
+func isSensitiveKey(key string) bool {
+	normalized := strings.ToLower(strings.NewReplacer("_", "", "-", "", " ", "").Replace(key))
+
+	if sensitiveKeys[normalized] {
+		return true
+	}
+
+	for i := range sensitiveKeyParts {
+		if strings.Contains(normalized, sensitiveKeyParts[i]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func writeJSONString(buf *bytes.Buffer, str string) {
+	b, _ := json.Marshal(str)
+	buf.Write(b)
+}
+
+// ****** Struct redaction ******
+
+type redactor struct {
+	// seen copies of pointers and maps, to stop cycles
+	seen map[redactKey]reflect.Value
+	// keys redact sensitive keys and secrets in strings as well, not only tagged fields
+	keys bool
+}
+
+// redactKey pointer to struct shares address with pointer to its first field, so type is part of key
+type redactKey struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// copy returns a deep copy of v with secret fields redacted
+func (r *redactor) copy(v reflect.Value) reflect.Value {
+	if !v.IsValid() || isMarshalerType(v.Type()) {
+		return v
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+
+		key := redactKey{ptr: v.Pointer(), typ: v.Type()}
+		if res, ok := r.seen[key]; ok {
+			return res
+		}
+
+		res := reflect.New(v.Type().Elem())
+		r.seen[key] = res
+		res.Elem().Set(r.copy(v.Elem()))
+		return res
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+
+		res := reflect.New(v.Type()).Elem()
+		res.Set(r.copy(v.Elem()))
+		return res
+	case reflect.Struct:
+		res := reflect.New(v.Type()).Elem()
+		res.Set(v)
+		r.redactStruct(res)
+		return res
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+
+		res := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			res.Index(i).Set(r.copy(v.Index(i)))
+		}
+		return res
+	case reflect.Array:
+		res := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			res.Index(i).Set(r.copy(v.Index(i)))
+		}
+		return res
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+
+		key := redactKey{ptr: v.Pointer(), typ: v.Type()}
+		if res, ok := r.seen[key]; ok {
+			return res
+		}
+
+		res := reflect.MakeMapWithSize(v.Type(), v.Len())
+		r.seen[key] = res
+		iter := v.MapRange()
+		for iter.Next() {
+			if r.keys && iter.Key().Kind() == reflect.String && isSensitiveKey(iter.Key().String()) {
+				masked := reflect.New(v.Type().Elem()).Elem()
+				masked.Set(iter.Value())
+				maskField(masked)
+				res.SetMapIndex(iter.Key(), masked)
+				continue
+			}
+
+			res.SetMapIndex(iter.Key(), r.copy(iter.Value()))
+		}
+		return res
+	case reflect.String:
+		if r.keys {
+			res := reflect.New(v.Type()).Elem()
+			res.SetString(redactString(v.String()))
+			return res
+		}
+	}
+
+	return v
+}
+
+// redactStruct redact fields of addressable struct in place
+func (r *redactor) redactStruct(v reflect.Value) {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := v.Field(i)
+
+		if !field.CanSet() {
+			if !t.Field(i).Anonymous {
+				// unexported field, ignored by encoding/json
+				continue
+			}
+
+			// exported fields of unexported embedded type are still visible to encoding/json,
+			// v is an addressable copy so it is safe to write the field through its address
+			field = reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem()
+		}
+
+		if isSecretField(t.Field(i)) || (r.keys && isSensitiveField(t.Field(i))) {
+			maskField(field)
+			continue
+		}
+
+		field.Set(r.copy(field))
+	}
+}
+
+func isSecretField(field reflect.StructField) bool {
+	for _, opt := range strings.Split(field.Tag.Get("mof"), ",") {
+		if strings.TrimSpace(opt) == secretTag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isSensitiveField checks JSON name of field, see isSensitiveKey
+func isSensitiveField(field reflect.StructField) bool {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		if field.Anonymous {
+			// fields of embedded struct are checked one by one
+			return false
+		}
+		name = field.Name
+	}
+
+	return isSensitiveKey(name)
+}
+
+func maskField(field reflect.Value) {
+	switch {
+	case field.Kind() == reflect.String:
+		field.SetString(RedactedValue)
+	case field.Kind() == reflect.Interface && !field.IsNil() && reflect.TypeOf(RedactedValue).AssignableTo(field.Type()):
+		field.Set(reflect.ValueOf(RedactedValue))
+	case field.Kind() == reflect.Pointer && field.Type().Elem().Kind() == reflect.String && !field.IsNil():
+		res := reflect.New(field.Type().Elem())
+		res.Elem().SetString(RedactedValue)
+		field.Set(res)
+	default:
+		field.Set(reflect.Zero(field.Type()))
+	}
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+func isMarshalerType(t reflect.Type) bool {
+	return t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType)
+}
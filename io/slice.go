@@ -0,0 +1,119 @@
+/*
+ * Copyright (c) 2022 The Mof Authors
+ */
+
+package pio
+
+// Ptr returns pointer of a copy of in
+func Ptr[T any](in T) *T {
+	return &in
+}
+
+// Deref returns value of pointer, or de if pointer is nil
+func Deref[T any](in *T, de T) T {
+	if in == nil {
+		return de
+	}
+
+	return *in
+}
+
+// Dedup remove duplicated elements, order of first occurrence is kept
+func Dedup[T comparable](src []T) []T {
+	return DedupBy(src, func(e T) T {
+		return e
+	})
+}
+
+// DedupBy remove elements with duplicated key, order of first occurrence is kept
+func DedupBy[T any, K comparable](src []T, key func(T) K) []T {
+	m := make(map[K]bool, len(src))
+	res := make([]T, 0, len(src))
+
+	for i := range src {
+		k := key(src[i])
+		if _, exist := m[k]; !exist {
+			m[k] = true
+			res = append(res, src[i])
+		}
+	}
+
+	return res
+}
+
+// Contains checks whether key is in src
+func Contains[T comparable](src []T, key T) bool {
+	return IndexOf(src, key) >= 0
+}
+
+// IndexOf returns index of first key in src, or -1 if not found
+func IndexOf[T comparable](src []T, key T) int {
+	for i := range src {
+		if src[i] == key {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Filter returns elements which fn returns true
+func Filter[T any](src []T, fn func(T) bool) []T {
+	res := make([]T, 0)
+
+	for i := range src {
+		if fn(src[i]) {
+			res = append(res, src[i])
+		}
+	}
+
+	return res
+}
+
+// Map convert each element with fn
+func Map[T, R any](src []T, fn func(T) R) []R {
+	res := make([]R, 0, len(src))
+
+	for i := range src {
+		res = append(res, fn(src[i]))
+	}
+
+	return res
+}
+
+// GroupBy group elements by key, order of elements in each group is kept
+func GroupBy[T any, K comparable](src []T, key func(T) K) map[K][]T {
+	res := make(map[K][]T)
+
+	for i := range src {
+		k := key(src[i])
+		res[k] = append(res[k], src[i])
+	}
+
+	return res
+}
+
+// Chunk split src into chunks with at most size elements, the whole src is one chunk if size < 1
+func Chunk[T any](src []T, size int) [][]T {
+	res := make([][]T, 0)
+
+	if len(src) < 1 {
+		return res
+	}
+
+	if size < 1 {
+		// cap limited, so that appending to chunk does not overwrite src
+		return append(res, src[:len(src):len(src)])
+	}
+
+	for i := 0; i < len(src); i += size {
+		end := i + size
+		if end > len(src) {
+			end = len(src)
+		}
+
+		res = append(res, src[i:end:end])
+	}
+
+	return res
+}
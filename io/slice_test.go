@@ -0,0 +1,112 @@
+package pio
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDedupKeepsOrder(t *testing.T) {
+	got := Dedup([]string{"b", "a", "b", "c", "a"})
+	wanted := []string{"b", "a", "c"}
+	if !reflect.DeepEqual(got, wanted) {
+		t.Errorf("got %v, wanted %v", got, wanted)
+	}
+
+	if got := DedupInt64Slice([]int64{3, 1, 3, 2}); !reflect.DeepEqual(got, []int64{3, 1, 2}) {
+		t.Errorf("got %v, wanted %v", got, []int64{3, 1, 2})
+	}
+}
+
+func TestDedupBy(t *testing.T) {
+	got := DedupBy([]string{"aa", "b", "cc", "d"}, func(s string) int {
+		return len(s)
+	})
+	wanted := []string{"aa", "b"}
+	if !reflect.DeepEqual(got, wanted) {
+		t.Errorf("got %v, wanted %v", got, wanted)
+	}
+}
+
+func TestPtrAndDeref(t *testing.T) {
+	if got := Deref(Ptr(5), 1); got != 5 {
+		t.Errorf("got %d, wanted %d", got, 5)
+	}
+
+	if got := Deref[string](nil, "de"); got != "de" {
+		t.Errorf("got %q, wanted %q", got, "de")
+	}
+
+	if got := *Uint64P(7); got != 7 {
+		t.Errorf("got %d, wanted %d", got, 7)
+	}
+}
+
+func TestContainsAndIndexOf(t *testing.T) {
+	src := []string{"a", "b", "c"}
+
+	if ok, i := ContainsStringSlice(src, "c"); !ok || i != 2 {
+		t.Errorf("got %v %d, wanted true 2", ok, i)
+	}
+
+	if ok, i := ContainsStringSlice(src, "d"); ok || i != -1 {
+		t.Errorf("got %v %d, wanted false -1", ok, i)
+	}
+
+	if !Contains([]int{1, 2}, 2) {
+		t.Errorf("got false, wanted true")
+	}
+}
+
+func TestFilterMapGroupBy(t *testing.T) {
+	src := []int{1, 2, 3, 4, 5}
+
+	even := Filter(src, func(i int) bool {
+		return i%2 == 0
+	})
+	if !reflect.DeepEqual(even, []int{2, 4}) {
+		t.Errorf("got %v, wanted %v", even, []int{2, 4})
+	}
+
+	doubled := Map(src, func(i int) int {
+		return i * 2
+	})
+	if !reflect.DeepEqual(doubled, []int{2, 4, 6, 8, 10}) {
+		t.Errorf("got %v, wanted %v", doubled, []int{2, 4, 6, 8, 10})
+	}
+
+	groups := GroupBy(src, func(i int) bool {
+		return i%2 == 0
+	})
+	if !reflect.DeepEqual(groups[false], []int{1, 3, 5}) {
+		t.Errorf("got %v, wanted %v", groups[false], []int{1, 3, 5})
+	}
+}
+
+func TestChunk(t *testing.T) {
+	tests := []struct {
+		size   int
+		wanted [][]int
+	}{
+		{size: 2, wanted: [][]int{{1, 2}, {3, 4}, {5}}},
+		{size: 5, wanted: [][]int{{1, 2, 3, 4, 5}}},
+		{size: 0, wanted: [][]int{{1, 2, 3, 4, 5}}},
+	}
+
+	for _, tt := range tests {
+		got := Chunk([]int{1, 2, 3, 4, 5}, tt.size)
+		if !reflect.DeepEqual(got, tt.wanted) {
+			t.Errorf("size %d: got %v, wanted %v", tt.size, got, tt.wanted)
+		}
+	}
+
+	src := make([]int, 2, 4)
+	whole := Chunk(src, 0)
+	_ = append(whole[0], 9)
+	if src[:3][2] != 0 {
+		t.Errorf("got %v, wanted src untouched by append to chunk", src[:3])
+	}
+
+	if got := Chunk([]int{}, 2); len(got) != 0 {
+		t.Errorf("got %v, wanted empty", got)
+	}
+}
@@ -0,0 +1,352 @@
+/*
+ * Copyright (c) 2022 The Mof Authors
+ */
+
+package output
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// valueColumn column name of scalar values, like elements of []string
+const valueColumn = "value"
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Table flattened rows with header
+type Table struct {
+	Header []string
+	Rows   [][]string
+}
+
+// Flatten convert struct, slice of struct or map into Table
+//
+// Each element of slice or array is a row, anything else is a single row.
+// Columns are named by json tag, or field name if there is no tag, nested
+// struct fields are joined with dot like "period.start". Slices and maps
+// inside a row are written as compact JSON. Types implementing
+// encoding.TextMarshaler or json.Marshaler, like time.Time, are single columns.
+func Flatten(v interface{}) (*Table, error) {
+	b := &tableBuilder{
+		index:    make(map[string]int),
+		visiting: make(map[reflect.Type]bool),
+	}
+
+	val := reflect.ValueOf(v)
+	for val.Kind() == reflect.Interface || (val.Kind() == reflect.Pointer && !val.IsNil() && !isLeafType(val.Type())) {
+		val = val.Elem()
+	}
+
+	if !val.IsValid() {
+		return b.table(), nil
+	}
+
+	if val.Kind() == reflect.Pointer && val.IsNil() {
+		// header only
+		if err := b.flatten("", val, nil); err != nil {
+			return nil, err
+		}
+		return b.table(), nil
+	}
+
+	if (val.Kind() == reflect.Slice || val.Kind() == reflect.Array) && !isLeafType(val.Type()) && !isBytes(val.Type()) {
+		// columns come from element type, so that header is written for empty slice as well
+		if err := b.flatten("", reflect.Zero(val.Type().Elem()), nil); err != nil {
+			return nil, err
+		}
+
+		for i := 0; i < val.Len(); i++ {
+			row := make(map[string]string)
+			if err := b.flatten("", val.Index(i), row); err != nil {
+				return nil, err
+			}
+			b.rows = append(b.rows, row)
+		}
+
+		return b.table(), nil
+	}
+
+	row := make(map[string]string)
+	if err := b.flatten("", val, row); err != nil {
+		return nil, err
+	}
+	b.rows = append(b.rows, row)
+
+	return b.table(), nil
+}
+
+type tableBuilder struct {
+	header []string
+	index  map[string]int
+	rows   []map[string]string
+
+	// visiting struct types reached from nil pointers, to stop recursive types
+	visiting map[reflect.Type]bool
+}
+
+func (b *tableBuilder) table() *Table {
+	res := &Table{
+		Header: b.header,
+		Rows:   make([][]string, 0, len(b.rows)),
+	}
+
+	if res.Header == nil {
+		res.Header = make([]string, 0)
+	}
+
+	for i := range b.rows {
+		row := make([]string, len(b.header))
+		for j := range b.header {
+			row[j] = b.rows[i][b.header[j]]
+		}
+		res.Rows = append(res.Rows, row)
+	}
+
+	return res
+}
+
+// set register column, and set value if row is not nil
+func (b *tableBuilder) set(column, value string, row map[string]string) {
+	if column == "" {
+		column = valueColumn
+	}
+
+	if _, ok := b.index[column]; !ok {
+		b.index[column] = len(b.header)
+		b.header = append(b.header, column)
+	}
+
+	if row != nil {
+		row[column] = value
+	}
+}
+
+// flatten write columns of v into row, only columns are registered if row is nil
+func (b *tableBuilder) flatten(prefix string, v reflect.Value, row map[string]string) error {
+	for v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	if !v.IsValid() || (v.Kind() == reflect.Interface && v.IsNil()) {
+		b.set(prefix, "", row)
+		return nil
+	}
+
+	if isLeafType(v.Type()) {
+		str, err := leafString(v)
+		if err != nil {
+			return err
+		}
+		b.set(prefix, str, row)
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			return b.flatten(prefix, v.Elem(), row)
+		}
+
+		elem := v.Type().Elem()
+		if elem.Kind() != reflect.Struct || b.visiting[elem] {
+			b.set(prefix, "", row)
+			return nil
+		}
+
+		// nil struct pointer, register columns with empty values
+		b.visiting[elem] = true
+		defer delete(b.visiting, elem)
+		return b.flatten(prefix, reflect.Zero(elem), nil)
+	case reflect.Struct:
+		return b.flattenStruct(prefix, v, row)
+	case reflect.Map:
+		if prefix != "" {
+			break
+		}
+		return b.flattenMap(v, row)
+	}
+
+	str, err := leafString(v)
+	if err != nil {
+		return err
+	}
+
+	b.set(prefix, str, row)
+	return nil
+}
+
+func (b *tableBuilder) flattenStruct(prefix string, v reflect.Value, row map[string]string) error {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		name, skip := fieldName(field)
+		if skip {
+			continue
+		}
+
+		if field.Anonymous && name == "" {
+			// embedded struct, fields are promoted like encoding/json
+			if err := b.flatten(prefix, v.Field(i), row); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+
+		if err := b.flatten(name, v.Field(i), row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// flattenMap top level map, each key is a column ordered by key
+func (b *tableBuilder) flattenMap(v reflect.Value, row map[string]string) error {
+	keys := v.MapKeys()
+	names := make(map[string]reflect.Value, len(keys))
+	sorted := make([]string, 0, len(keys))
+
+	for i := range keys {
+		name := fmt.Sprint(keys[i].Interface())
+		names[name] = keys[i]
+		sorted = append(sorted, name)
+	}
+
+	sort.Strings(sorted)
+
+	for _, name := range sorted {
+		if err := b.flatten(name, v.MapIndex(names[name]), row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fieldName name from json tag, skip unexported fields and fields tagged with "-"
+//
+// Empty name is returned for embedded struct without name in tag.
+func fieldName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", true
+	}
+
+	name := strings.Split(tag, ",")[0]
+
+	if field.Anonymous && name == "" {
+		t := field.Type
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+
+		if t.Kind() == reflect.Struct {
+			return "", false
+		}
+	}
+
+	if !field.IsExported() {
+		return "", true
+	}
+
+	return name, false
+}
+
+func isLeafType(t reflect.Type) bool {
+	return t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) ||
+		(t.Kind() != reflect.Pointer && (reflect.PointerTo(t).Implements(jsonMarshalerType) ||
+			reflect.PointerTo(t).Implements(textMarshalerType)))
+}
+
+func isBytes(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8
+}
+
+// leafString format single cell
+func leafString(v reflect.Value) (string, error) {
+	if v.Kind() == reflect.Pointer && v.IsNil() {
+		return "", nil
+	}
+
+	if isLeafType(v.Type()) {
+		if v.Kind() != reflect.Pointer && !v.CanAddr() {
+			// pointer receiver needs addressable value
+			addressable := reflect.New(v.Type()).Elem()
+			addressable.Set(v)
+			v = addressable
+		}
+
+		var iface interface{}
+		if v.CanAddr() {
+			iface = v.Addr().Interface()
+		} else {
+			iface = v.Interface()
+		}
+
+		if tm, ok := iface.(encoding.TextMarshaler); ok {
+			text, err := tm.MarshalText()
+			return string(text), err
+		}
+
+		return compactJSON(iface)
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits()), nil
+	case reflect.Slice, reflect.Map:
+		if v.IsNil() {
+			return "", nil
+		}
+	}
+
+	return compactJSON(v.Interface())
+}
+
+// compactJSON marshal v, JSON string is unquoted and null is empty
+func compactJSON(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	if string(b) == "null" {
+		return "", nil
+	}
+
+	if len(b) > 0 && b[0] == '"' {
+		var str string
+		if err := json.Unmarshal(b, &str); err == nil {
+			return str, nil
+		}
+	}
+
+	return string(b), nil
+}
@@ -0,0 +1,239 @@
+/*
+ * Copyright (c) 2022 The Mof Authors
+ */
+
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format name of output format, usually comes from --output flag of CLI
+type Format string
+
+const (
+	FormatJSON     Format = "json"
+	FormatYAML     Format = "yaml"
+	FormatCSV      Format = "csv"
+	FormatTable    Format = "table"
+	FormatMarkdown Format = "markdown"
+)
+
+// formatAliases maps accepted names to Format
+var formatAliases = map[string]Format{
+	"":         FormatJSON,
+	"json":     FormatJSON,
+	"yaml":     FormatYAML,
+	"yml":      FormatYAML,
+	"csv":      FormatCSV,
+	"table":    FormatTable,
+	"text":     FormatTable,
+	"markdown": FormatMarkdown,
+	"md":       FormatMarkdown,
+}
+
+// Formats returns all supported formats
+func Formats() []Format {
+	return []Format{FormatJSON, FormatYAML, FormatCSV, FormatTable, FormatMarkdown}
+}
+
+// ParseFormat parse format name, case insensitive
+//
+// Aliases yml, text and md are accepted, empty name means json.
+func ParseFormat(str string) (Format, error) {
+	if res, ok := formatAliases[strings.ToLower(strings.TrimSpace(str))]; ok {
+		return res, nil
+	}
+
+	return "", errors.New(fmt.Sprintf("unsupported output format: %q, should be one of %v", str, Formats()))
+}
+
+// Encoder writes values to underlying io.Writer
+type Encoder interface {
+	Encode(v interface{}) error
+}
+
+// NewEncoder create Encoder of format
+//
+// json and yaml are indented with 2 spaces, yaml follows json tags as well. csv, table and markdown flatten
+// struct, slice of struct or map into columns, see Flatten.
+func NewEncoder(w io.Writer, format Format) (Encoder, error) {
+	switch format {
+	case FormatJSON:
+		return &jsonEncoder{w: w}, nil
+	case FormatYAML:
+		return &yamlEncoder{w: w}, nil
+	case FormatCSV:
+		return &tableEncoder{w: w, render: renderCSV}, nil
+	case FormatTable:
+		return &tableEncoder{w: w, render: renderTable}, nil
+	case FormatMarkdown:
+		return &tableEncoder{w: w, render: renderMarkdown}, nil
+	}
+
+	return nil, errors.New(fmt.Sprintf("unsupported output format: %q, should be one of %v", format, Formats()))
+}
+
+// Write encode v with format into w
+func Write(w io.Writer, format Format, v interface{}) error {
+	enc, err := NewEncoder(w, format)
+	if err != nil {
+		return err
+	}
+
+	return enc.Encode(v)
+}
+
+// ****** JSON ******
+
+type jsonEncoder struct {
+	w io.Writer
+}
+
+func (e *jsonEncoder) Encode(v interface{}) error {
+	enc := json.NewEncoder(e.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// ****** YAML ******
+
+type yamlEncoder struct {
+	w io.Writer
+}
+
+// Encode v is marshalled to JSON first, so that json tags and MarshalJSON are respected
+// the same way as other formats, and order of fields is kept
+func (e *yamlEncoder) Encode(v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	node := &yaml.Node{}
+	if err := yaml.Unmarshal(b, node); err != nil {
+		return err
+	}
+	resetStyle(node)
+
+	enc := yaml.NewEncoder(e.w)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(node); err != nil {
+		return err
+	}
+
+	return enc.Close()
+}
+
+// resetStyle drop flow and quoting style inherited from JSON, quotes are added back where needed
+func resetStyle(node *yaml.Node) {
+	node.Style = 0
+
+	for i := range node.Content {
+		resetStyle(node.Content[i])
+	}
+}
+
+// ****** Tabular ******
+
+type tableEncoder struct {
+	w      io.Writer
+	render func(w io.Writer, t *Table) error
+}
+
+func (e *tableEncoder) Encode(v interface{}) error {
+	t, err := Flatten(v)
+	if err != nil {
+		return err
+	}
+
+	if len(t.Header) < 1 {
+		return nil
+	}
+
+	return e.render(e.w, t)
+}
+
+func renderCSV(w io.Writer, t *Table) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(t.Header); err != nil {
+		return err
+	}
+
+	if err := writer.WriteAll(t.Rows); err != nil {
+		return err
+	}
+
+	return writer.Error()
+}
+
+func renderTable(w io.Writer, t *Table) error {
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	cleaner := strings.NewReplacer("\t", " ", "\r", " ", "\n", " ")
+	writeRow := func(row []string) error {
+		cells := make([]string, 0, len(row))
+		for i := range row {
+			cells = append(cells, cleaner.Replace(row[i]))
+		}
+
+		_, err := fmt.Fprintln(writer, strings.Join(cells, "\t"))
+		return err
+	}
+
+	if err := writeRow(t.Header); err != nil {
+		return err
+	}
+
+	for i := range t.Rows {
+		if err := writeRow(t.Rows[i]); err != nil {
+			return err
+		}
+	}
+
+	return writer.Flush()
+}
+
+func renderMarkdown(w io.Writer, t *Table) error {
+	escaper := strings.NewReplacer("|", "\\|", "\r\n", "<br>", "\n", "<br>")
+	writeRow := func(row []string) error {
+		cells := make([]string, 0, len(row))
+		for i := range row {
+			cells = append(cells, escaper.Replace(row[i]))
+		}
+
+		_, err := fmt.Fprintf(w, "| %s |\n", strings.Join(cells, " | "))
+		return err
+	}
+
+	if err := writeRow(t.Header); err != nil {
+		return err
+	}
+
+	sep := make([]string, len(t.Header))
+	for i := range sep {
+		sep[i] = "---"
+	}
+
+	if err := writeRow(sep); err != nil {
+		return err
+	}
+
+	for i := range t.Rows {
+		if err := writeRow(t.Rows[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
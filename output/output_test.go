@@ -0,0 +1,171 @@
+package output
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type period struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+type base struct {
+	Provider string `json:"provider"`
+}
+
+type costItem struct {
+	base
+	Name    string            `json:"name"`
+	Cost    float64           `json:"cost"`
+	Period  *period           `json:"period"`
+	Tags    map[string]string `json:"tags,omitempty"`
+	At      time.Time         `json:"at"`
+	Ignored string            `json:"-"`
+	hidden  string
+}
+
+func testItems() []costItem {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	return []costItem{
+		{
+			base:   base{Provider: "aws"},
+			Name:   "ec2|m5",
+			Cost:   1.5,
+			Period: &period{Start: "2024-01-01", End: "2024-01-31"},
+			Tags:   map[string]string{"env": "prod"},
+			At:     at,
+		},
+		{
+			base: base{Provider: "gcp"},
+			Name: "gce",
+			Cost: 2,
+			At:   at,
+		},
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := map[string]Format{
+		"":         FormatJSON,
+		"YAML":     FormatYAML,
+		"yml":      FormatYAML,
+		" csv ":    FormatCSV,
+		"text":     FormatTable,
+		"md":       FormatMarkdown,
+		"markdown": FormatMarkdown,
+	}
+
+	for in, wanted := range tests {
+		got, err := ParseFormat(in)
+		if err != nil || got != wanted {
+			t.Errorf("got %q %v, wanted %q", got, err, wanted)
+		}
+	}
+
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Errorf("got nil, wanted error for xml")
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	table, err := Flatten(testItems())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantedHeader := []string{"provider", "name", "cost", "period.start", "period.end", "tags", "at"}
+	if !reflect.DeepEqual(table.Header, wantedHeader) {
+		t.Errorf("got %v, wanted %v", table.Header, wantedHeader)
+	}
+
+	wantedRows := [][]string{
+		{"aws", "ec2|m5", "1.5", "2024-01-01", "2024-01-31", `{"env":"prod"}`, "2024-01-02T03:04:05Z"},
+		{"gcp", "gce", "2", "", "", "", "2024-01-02T03:04:05Z"},
+	}
+	if !reflect.DeepEqual(table.Rows, wantedRows) {
+		t.Errorf("got %v, wanted %v", table.Rows, wantedRows)
+	}
+
+	empty, err := Flatten([]*costItem{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(empty.Header, wantedHeader) || len(empty.Rows) != 0 {
+		t.Errorf("got %v %v, wanted header only", empty.Header, empty.Rows)
+	}
+
+	scalars, err := Flatten([]string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(scalars.Header, []string{"value"}) || len(scalars.Rows) != 2 {
+		t.Errorf("got %v %v, wanted single value column", scalars.Header, scalars.Rows)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	items := testItems()[1:]
+	items[0].Name = "a|b"
+
+	tests := []struct {
+		format Format
+		wanted string
+	}{
+		{
+			format: FormatCSV,
+			wanted: "provider,name,cost,period.start,period.end,tags,at\n" +
+				"gcp,a|b,2,,,,2024-01-02T03:04:05Z\n",
+		},
+		{
+			format: FormatMarkdown,
+			wanted: "| provider | name | cost | period.start | period.end | tags | at |\n" +
+				"| --- | --- | --- | --- | --- | --- | --- |\n" +
+				"| gcp | a\\|b | 2 |  |  |  | 2024-01-02T03:04:05Z |\n",
+		},
+		{
+			format: FormatYAML,
+			wanted: "- provider: gcp\n  name: a|b\n  cost: 2\n  period: null\n  at: \"2024-01-02T03:04:05Z\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		buf := &bytes.Buffer{}
+		if err := Write(buf, tt.format, items); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := buf.String(); got != tt.wanted {
+			t.Errorf("%s: got %q, wanted %q", tt.format, got, tt.wanted)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	if err := Write(buf, FormatTable, []period{{Start: "2024-01-01", End: "2024-01-31"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	wanted := "start       end\n2024-01-01  2024-01-31\n"
+	if got := buf.String(); got != wanted {
+		t.Errorf("got %q, wanted %q", got, wanted)
+	}
+
+	buf.Reset()
+	if err := Write(buf, FormatJSON, period{Start: "2024-01-01"}); err != nil {
+		t.Fatal(err)
+	}
+
+	wanted = "{\n  \"start\": \"2024-01-01\",\n  \"end\": \"\"\n}\n"
+	if got := buf.String(); got != wanted {
+		t.Errorf("got %q, wanted %q", got, wanted)
+	}
+
+	if err := Write(buf, FormatJSON, func() {}); err == nil {
+		t.Errorf("got nil, wanted marshal error")
+	}
+}
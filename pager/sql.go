@@ -0,0 +1,57 @@
+package pager
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Value implements driver.Valuer, Page is stored as JSON
+func (p Page) Value() (driver.Value, error) {
+	bytes, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(bytes), nil
+}
+
+// Scan implements sql.Scanner, accepts JSON or base64 encoded Page produced by Encode
+//
+// NULL and empty string are the default page, same as DecodeToPage("").
+func (p *Page) Scan(src interface{}) error {
+	var str string
+
+	switch v := src.(type) {
+	case nil:
+		str = ""
+	case []byte:
+		str = string(v)
+	case string:
+		str = v
+	default:
+		return errors.New(fmt.Sprintf("failed to scan %T into Page", src))
+	}
+
+	str = strings.TrimSpace(str)
+
+	if strings.HasPrefix(str, "{") {
+		res := Page{}
+		if err := json.Unmarshal([]byte(str), &res); err != nil {
+			return err
+		}
+
+		*p = res
+		return nil
+	}
+
+	res, err := DecodeToPage(str)
+	if err != nil {
+		return err
+	}
+
+	*p = *res
+	return nil
+}
@@ -0,0 +1,49 @@
+package pager
+
+import (
+	"testing"
+)
+
+func TestPageValueAndScan(t *testing.T) {
+	p := Page{PageNum: 3, PageSize: 20}
+
+	v, err := p.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v != `{"pageNum":3,"pageSize":20}` {
+		t.Errorf("got %v, wanted %q", v, `{"pageNum":3,"pageSize":20}`)
+	}
+
+	for _, src := range []interface{}{v, []byte(v.(string)), p.Encode()} {
+		res := Page{}
+		if err := res.Scan(src); err != nil {
+			t.Fatal(err)
+		}
+
+		if res != p {
+			t.Errorf("got %+v, wanted %+v", res, p)
+		}
+	}
+}
+
+func TestPageScanDefault(t *testing.T) {
+	wanted := Page{PageNum: 1, PageSize: defaultPageSize}
+
+	for _, src := range []interface{}{nil, "", []byte("  ")} {
+		res := Page{PageNum: 9}
+		if err := res.Scan(src); err != nil {
+			t.Fatal(err)
+		}
+
+		if res != wanted {
+			t.Errorf("got %+v, wanted %+v for %#v", res, wanted, src)
+		}
+	}
+
+	res := Page{}
+	if err := res.Scan(12); err == nil {
+		t.Errorf("got nil, wanted error for int")
+	}
+}
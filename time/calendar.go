@@ -0,0 +1,298 @@
+/*
+ * Copyright (c) 2022 The Mof Authors
+ */
+
+package ptime
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Calendar weekends and holidays used to count business days
+//
+// Holidays are kept at day granularity with YYYY-MM-DD keys.
+// Zero value is ready to use, with neither weekend nor holiday.
+type Calendar struct {
+	weekend  map[time.Weekday]bool
+	holidays map[string]string
+}
+
+// NewCalendar create Calendar without holidays, Saturday and Sunday are weekends if nothing provided
+func NewCalendar(weekend ...time.Weekday) *Calendar {
+	if len(weekend) < 1 {
+		weekend = []time.Weekday{time.Saturday, time.Sunday}
+	}
+
+	res := &Calendar{
+		weekend:  map[time.Weekday]bool{},
+		holidays: map[string]string{},
+	}
+
+	for i := range weekend {
+		res.weekend[weekend[i]] = true
+	}
+
+	return res
+}
+
+// AddHoliday add holiday, day could be any layout accepted by StringToTime
+func (c *Calendar) AddHoliday(day, name string) error {
+	ts, err := StringToTime(day)
+	if err != nil {
+		return err
+	}
+
+	c.setHoliday(ts, name)
+	return nil
+}
+
+func (c *Calendar) setHoliday(ts time.Time, name string) {
+	if c.holidays == nil {
+		c.holidays = map[string]string{}
+	}
+
+	c.holidays[TimeToLayoutDay(ts)] = name
+}
+
+// Holidays returns a copy of holidays, YYYY-MM-DD -> name
+func (c *Calendar) Holidays() map[string]string {
+	res := make(map[string]string, len(c.holidays))
+
+	for k, v := range c.holidays {
+		res[k] = v
+	}
+
+	return res
+}
+
+// LoadHolidaysFile load holidays from .csv or .ics file
+func (c *Calendar) LoadHolidaysFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return c.LoadHolidaysCSV(file)
+	case ".ics", ".ical":
+		return c.LoadHolidaysICS(file)
+	}
+
+	return errors.New(fmt.Sprintf("unsupported holiday file: %s, should be .csv or .ics", path))
+}
+
+// LoadHolidaysCSV load holidays from CSV with format of date,name
+//
+// Header line, empty lines and lines start with # are skipped, name is optional.
+func (c *Calendar) LoadHolidaysCSV(r io.Reader) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if len(record) < 1 || len(strings.TrimSpace(record[0])) < 1 {
+			continue
+		}
+
+		if first && strings.EqualFold(strings.TrimSpace(record[0]), "date") {
+			continue
+		}
+
+		name := ""
+		if len(record) > 1 {
+			name = strings.TrimSpace(record[1])
+		}
+
+		if err := c.AddHoliday(strings.TrimSpace(record[0]), name); err != nil {
+			// line in file, comments and empty lines are counted as well
+			line, _ := reader.FieldPos(0)
+			return errors.New(fmt.Sprintf("invalid holiday at line %d: %s", line, err.Error()))
+		}
+	}
+}
+
+// LoadHolidaysICS load all-day events from iCalendar as holidays
+//
+// Every day between DTSTART and DTEND (exclusive) is added, recurrence rules are not supported.
+func (c *Calendar) LoadHolidaysICS(r io.Reader) error {
+	lines, err := unfoldICSLines(r)
+	if err != nil {
+		return err
+	}
+
+	inEvent := false
+	var start, end time.Time
+	name := ""
+
+	for _, line := range lines {
+		switch {
+		case line == "BEGIN:VEVENT":
+			inEvent = true
+			start, end, name = time.Time{}, time.Time{}, ""
+		case line == "END:VEVENT":
+			inEvent = false
+
+			if start.IsZero() {
+				return errors.New("invalid VEVENT, DTSTART is missing")
+			}
+
+			if end.IsZero() || !end.After(start) {
+				end = start.AddDate(0, 0, 1)
+			}
+
+			for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+				c.setHoliday(day, name)
+			}
+		case inEvent:
+			key, value := splitICSProperty(line)
+
+			switch key {
+			case "DTSTART":
+				if start, err = parseICSDate(value); err != nil {
+					return err
+				}
+			case "DTEND":
+				if end, err = parseICSDate(value); err != nil {
+					return err
+				}
+			case "SUMMARY":
+				name = strings.ReplaceAll(value, "\\,", ",")
+			}
+		}
+	}
+
+	return nil
+}
+
+// IsWeekend checks whether ts is a weekend
+func (c *Calendar) IsWeekend(ts time.Time) bool {
+	return c.weekend[ts.Weekday()]
+}
+
+// IsHoliday checks whether ts is a holiday
+func (c *Calendar) IsHoliday(ts time.Time) bool {
+	_, ok := c.holidays[TimeToLayoutDay(ts)]
+	return ok
+}
+
+// IsBusinessDay checks whether ts is neither weekend nor holiday
+func (c *Calendar) IsBusinessDay(ts time.Time) bool {
+	return !c.IsWeekend(ts) && !c.IsHoliday(ts)
+}
+
+// BusinessDays returns business days in time period with layout YYYY-MM-DD
+func (c *Calendar) BusinessDays(tp *TimePeriod) ([]string, error) {
+	sp, err := tp.toSpan()
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]string, 0)
+	for day := sp.start; day.Before(sp.end); day = day.AddDate(0, 0, 1) {
+		if c.IsBusinessDay(day) {
+			res = append(res, TimeToLayoutDay(day))
+		}
+	}
+
+	return res, nil
+}
+
+// CountBusinessDays how many business days in time period
+func (c *Calendar) CountBusinessDays(tp *TimePeriod) (int, error) {
+	res, err := c.BusinessDays(tp)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(res), nil
+}
+
+// AddBusinessDays move ts forward by n business days, or backward if n is negative
+//
+// Clock of ts is kept. Error is returned if every day of week is weekend.
+func (c *Calendar) AddBusinessDays(ts time.Time, n int) (time.Time, error) {
+	if len(c.weekend) >= 7 {
+		return ts, errors.New("no business day in calendar, every day is weekend")
+	}
+
+	step := 1
+	if n < 0 {
+		step, n = -1, -n
+	}
+
+	for n > 0 {
+		ts = ts.AddDate(0, 0, step)
+		if c.IsBusinessDay(ts) {
+			n--
+		}
+	}
+
+	return ts, nil
+}
+
+// unfoldICSLines join folded lines of iCalendar, continuation lines start with space or tab
+func unfoldICSLines(r io.Reader) ([]string, error) {
+	res := make([]string, 0)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(res) > 0 {
+			res[len(res)-1] += line[1:]
+			continue
+		}
+
+		res = append(res, line)
+	}
+
+	return res, scanner.Err()
+}
+
+// splitICSProperty split DTSTART;VALUE=DATE:20240101 into DTSTART and 20240101
+func splitICSProperty(line string) (string, string) {
+	i := strings.Index(line, ":")
+	if i < 0 {
+		return line, ""
+	}
+
+	key := line[:i]
+	if j := strings.Index(key, ";"); j >= 0 {
+		key = key[:j]
+	}
+
+	return strings.ToUpper(key), line[i+1:]
+}
+
+// parseICSDate parse 20240101 or 20240101T000000Z, only date part is used
+func parseICSDate(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, errors.New(fmt.Sprintf("invalid iCalendar date: %s", value))
+	}
+
+	ts, err := time.Parse("20060102", value[:8])
+	if err != nil {
+		return time.Time{}, errors.New(fmt.Sprintf("invalid iCalendar date: %s", value))
+	}
+
+	return ts, nil
+}
@@ -0,0 +1,90 @@
+package ptime
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCalendarBusinessDays(t *testing.T) {
+	cal := NewCalendar()
+
+	err := cal.LoadHolidaysCSV(strings.NewReader("date,name\n2024-1-1,New Year\n# comment\n2024-01-15,MLK Day\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	count, err := cal.CountBusinessDays(&TimePeriod{Start: "2024-01", End: "2024-01"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if count != 21 {
+		t.Errorf("got %d, wanted %d", count, 21)
+	}
+
+	// Friday 2024-01-12 + 1 business day skips weekend and MLK day
+	ts, _ := cal.AddBusinessDays(time.Date(2024, 1, 12, 9, 0, 0, 0, time.UTC), 1)
+	if !ts.Equal(time.Date(2024, 1, 16, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("got %s, wanted 2024-01-16", ts)
+	}
+
+	ts, _ = cal.AddBusinessDays(ts, -1)
+	if !ts.Equal(time.Date(2024, 1, 12, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("got %s, wanted 2024-01-12", ts)
+	}
+}
+
+func TestCalendarICS(t *testing.T) {
+	ics := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT",
+		"DTSTART;VALUE=DATE:20241001",
+		"DTEND;VALUE=DATE:20241004",
+		"SUMMARY:National",
+		"  Day",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	// Friday and Saturday are weekends
+	cal := NewCalendar(time.Friday, time.Saturday)
+	if err := cal.LoadHolidaysICS(strings.NewReader(ics)); err != nil {
+		t.Fatal(err)
+	}
+
+	days, _ := cal.BusinessDays(&TimePeriod{Start: "2024-09-30", End: "2024-10-06"})
+	expected := []string{"2024-09-30", "2024-10-06"}
+	if strings.Join(days, ",") != strings.Join(expected, ",") {
+		t.Errorf("got %v, wanted %v", days, expected)
+	}
+
+	if cal.Holidays()["2024-10-03"] != "National Day" {
+		t.Errorf("got %q, wanted %q", cal.Holidays()["2024-10-03"], "National Day")
+	}
+}
+
+func TestCalendarZeroValue(t *testing.T) {
+	c := Calendar{}
+
+	if err := c.AddHoliday("2024-01-01", "New Year"); err != nil {
+		t.Fatal(err)
+	}
+
+	if !c.IsHoliday(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("got false, wanted 2024-01-01 to be holiday")
+	}
+
+	if c.IsWeekend(time.Date(2024, 1, 6, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("got true, wanted no weekend in zero value")
+	}
+}
+
+func TestCalendarCSVErrorLine(t *testing.T) {
+	in := "date,name\n# comment\n\n2024-01-01,New Year\nnot-a-date,Oops\n"
+
+	err := NewCalendar().LoadHolidaysCSV(strings.NewReader(in))
+	if err == nil || !strings.Contains(err.Error(), "line 5") {
+		t.Errorf("got %v, wanted error at line 5", err)
+	}
+}
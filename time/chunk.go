@@ -0,0 +1,106 @@
+/*
+ * Copyright (c) 2022 The Mof Authors
+ */
+
+package ptime
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ChunkAlignment decide where a chunk is allowed to start and end
+type ChunkAlignment int
+
+const (
+	// AlignNone chunks are cut only by MaxDays or MaxMonths
+	AlignNone ChunkAlignment = iota
+	// AlignMonth chunks never cross month boundary
+	AlignMonth
+)
+
+// ChunkProfile limits of a single query window
+//
+// MaxDays: max number of days in a chunk, 0 means no limit
+// MaxMonths: max number of natural months in a chunk, 0 means no limit
+// Alignment: whether chunk should be aligned to boundary of month
+type ChunkProfile struct {
+	MaxDays   int
+	MaxMonths int
+	Alignment ChunkAlignment
+}
+
+var (
+	// AwsChunkProfile AWS Cost Explorer, keep each query within 12 months
+	AwsChunkProfile = ChunkProfile{MaxMonths: 12, Alignment: AlignMonth}
+	// GcpChunkProfile GCP BigQuery billing export is partitioned by month
+	GcpChunkProfile = ChunkProfile{MaxMonths: 1, Alignment: AlignMonth}
+	// AlibabaChunkProfile Alibaba bill APIs accept one billing cycle per call
+	AlibabaChunkProfile = ChunkProfile{MaxMonths: 1, Alignment: AlignMonth}
+	// UCloudChunkProfile UCloud bill APIs accept one billing cycle per call
+	UCloudChunkProfile = ChunkProfile{MaxMonths: 1, Alignment: AlignMonth}
+	// LinodeChunkProfile Linode invoices are issued monthly
+	LinodeChunkProfile = ChunkProfile{MaxMonths: 1, Alignment: AlignMonth}
+)
+
+// Chunk split TimePeriod into windows which satisfy profile
+//
+// Each returned TimePeriod follows the same inclusive YYYY-MM-DD semantics as TimePeriod.
+// For example, with GcpChunkProfile:
+// [2024-01-15, 2024-03-10] => [2024-01-15, 2024-01-31], [2024-02-01, 2024-02-29], [2024-03-01, 2024-03-10]
+func (t *TimePeriod) Chunk(profile ChunkProfile) ([]*TimePeriod, error) {
+	if profile.MaxDays < 0 || profile.MaxMonths < 0 {
+		return nil, errors.New(fmt.Sprintf("invalid chunk profile, maxDays: %d, maxMonths: %d",
+			profile.MaxDays, profile.MaxMonths))
+	}
+
+	sp, err := t.toSpan()
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*TimePeriod, 0)
+
+	for curr := sp.start; curr.Before(sp.end); {
+		next := nextChunkEnd(curr, profile)
+		if next.After(sp.end) {
+			next = sp.end
+		}
+
+		res = append(res, span{start: curr, end: next}.toTimePeriod())
+		curr = next
+	}
+
+	return res, nil
+}
+
+// nextChunkEnd returns exclusive end of chunk which starts from curr,
+// zero time means no limit
+func nextChunkEnd(curr time.Time, profile ChunkProfile) time.Time {
+	var res time.Time
+
+	if profile.MaxMonths > 0 {
+		if profile.Alignment == AlignMonth {
+			res = time.Date(curr.Year(), curr.Month()+time.Month(profile.MaxMonths), 1, 0, 0, 0, 0, time.UTC)
+		} else {
+			res = AddMonths(curr, profile.MaxMonths, MonthClamp)
+		}
+	} else if profile.Alignment == AlignMonth {
+		res = time.Date(curr.Year(), curr.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	if profile.MaxDays > 0 {
+		byDays := curr.AddDate(0, 0, profile.MaxDays)
+		if res.IsZero() || byDays.Before(res) {
+			res = byDays
+		}
+	}
+
+	if res.IsZero() {
+		// no limit at all, never stop
+		res = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+	}
+
+	return res
+}
@@ -0,0 +1,60 @@
+package ptime
+
+import (
+	"testing"
+)
+
+func TestTimePeriodChunk(t *testing.T) {
+	tp := &TimePeriod{Start: "2024-01-15", End: "2024-03-10"}
+
+	res, err := tp.Chunk(GcpChunkProfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"2024-01-15->2024-01-31",
+		"2024-02-01->2024-02-29",
+		"2024-03-01->2024-03-10",
+	}
+
+	if len(res) != len(expected) {
+		t.Fatalf("got %d chunks, wanted %d", len(res), len(expected))
+	}
+
+	for i := range expected {
+		if res[i].String() != expected[i] {
+			t.Errorf("got %q, wanted %q", res[i].String(), expected[i])
+		}
+	}
+}
+
+func TestTimePeriodChunkByDays(t *testing.T) {
+	tp := &TimePeriod{Start: "2024-01-25", End: "2024-02-10"}
+
+	res, err := tp.Chunk(ChunkProfile{MaxDays: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"2024-01-25->2024-01-31",
+		"2024-02-01->2024-02-07",
+		"2024-02-08->2024-02-10",
+	}
+
+	if len(res) != len(expected) {
+		t.Fatalf("got %d chunks, wanted %d", len(res), len(expected))
+	}
+
+	for i := range expected {
+		if res[i].String() != expected[i] {
+			t.Errorf("got %q, wanted %q", res[i].String(), expected[i])
+		}
+	}
+
+	res, _ = tp.Chunk(ChunkProfile{MaxDays: 7, Alignment: AlignMonth})
+	if res[0].String() != "2024-01-25->2024-01-31" || res[1].String() != "2024-02-01->2024-02-07" {
+		t.Errorf("got %v, chunks should not cross month", res)
+	}
+}
@@ -0,0 +1,165 @@
+/*
+ * Copyright (c) 2022 The Mof Authors
+ */
+
+package ptime
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// timePeriodSeparator used by TimePeriod.String()
+const timePeriodSeparator = "->"
+
+// ParseTimePeriod parse compact form of TimePeriod produced by String(), like 2024-01-01->2024-01-31
+//
+// Start and End are normalized, see TimePeriod.Normalize.
+func ParseTimePeriod(str string) (*TimePeriod, error) {
+	parts := strings.Split(strings.TrimSpace(str), timePeriodSeparator)
+	if len(parts) != 2 {
+		return nil, errors.New(fmt.Sprintf("invalid time period: %q, should be format of start->end", str))
+	}
+
+	res := &TimePeriod{
+		Start: strings.TrimSpace(parts[0]),
+		End:   strings.TrimSpace(parts[1]),
+	}
+
+	if err := res.Normalize(); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// Normalize convert Start and End to YYYY-MM-DD or YYYY-MM, like 2024-1-5 => 2024-01-05
+//
+// Error is returned if any of them is in other format, or Start is after End.
+// An empty TimePeriod is left as it is.
+func (t *TimePeriod) Normalize() error {
+	if t.Start == "" && t.End == "" {
+		return nil
+	}
+
+	start, ok := normalizeDayOrMonth(t.Start)
+	if !ok {
+		return invalidFieldError("start", t.Start)
+	}
+
+	end, ok := normalizeDayOrMonth(t.End)
+	if !ok {
+		return invalidFieldError("end", t.End)
+	}
+
+	res := &TimePeriod{Start: start, End: end}
+	if _, err := res.toSpan(); err != nil {
+		return newValidationError("start", start, ReasonStartAfterEnd,
+			fmt.Sprintf("invalid time period: start %q is after end %q", start, end))
+	}
+
+	*t = *res
+	return nil
+}
+
+// UnmarshalJSON accepts {"start": "2024-01-01", "end": "2024-01-31"} or "2024-01-01->2024-01-31"
+func (t *TimePeriod) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	if bytes.Equal(b, []byte("null")) {
+		return nil
+	}
+
+	if len(b) > 0 && b[0] == '"' {
+		var str string
+		if err := json.Unmarshal(b, &str); err != nil {
+			return err
+		}
+
+		return t.UnmarshalText([]byte(str))
+	}
+
+	// alias type without UnmarshalJSON, to avoid recursion
+	type timePeriod TimePeriod
+
+	raw := timePeriod{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	res := TimePeriod(raw)
+	if err := res.Normalize(); err != nil {
+		return err
+	}
+
+	*t = res
+	return nil
+}
+
+// UnmarshalText accepts compact form like 2024-01-01->2024-01-31
+func (t *TimePeriod) UnmarshalText(text []byte) error {
+	if len(bytes.TrimSpace(text)) < 1 {
+		*t = TimePeriod{}
+		return nil
+	}
+
+	res, err := ParseTimePeriod(string(text))
+	if err != nil {
+		return err
+	}
+
+	*t = *res
+	return nil
+}
+
+// UnmarshalYAML accepts mapping with start and end, or compact form like 2024-01-01->2024-01-31
+func (t *TimePeriod) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		if value.Tag == "!!null" {
+			return nil
+		}
+
+		return t.UnmarshalText([]byte(value.Value))
+	}
+
+	raw := struct {
+		Start string `yaml:"start"`
+		End   string `yaml:"end"`
+	}{}
+
+	if err := value.Decode(&raw); err != nil {
+		return err
+	}
+
+	res := TimePeriod{Start: raw.Start, End: raw.End}
+	if err := res.Normalize(); err != nil {
+		return err
+	}
+
+	*t = res
+	return nil
+}
+
+// normalizeDayOrMonth convert YYYY-M-D or YYYY-M to YYYY-MM-DD or YYYY-MM
+func normalizeDayOrMonth(str string) (string, bool) {
+	str = strings.TrimSpace(str)
+
+	if res, ok := ToStdDayLayout(str); ok {
+		return res, true
+	}
+
+	if res, ok := ToStdMonthLayout(str); ok {
+		return res, true
+	}
+
+	return "", false
+}
+
+func invalidFieldError(field, value string) error {
+	return newValidationError(field, value, ReasonInvalidFormat,
+		fmt.Sprintf("invalid time period %s: %q, should be format of YYYY-MM-DD or YYYY-MM", field, value))
+}
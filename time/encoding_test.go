@@ -0,0 +1,77 @@
+package ptime
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestTimePeriodUnmarshalJSON(t *testing.T) {
+	cases := map[string]string{
+		`{"start": "2024-1-5", "end": "2024-2-1"}`: "2024-01-05->2024-02-01",
+		`{"start": "2024-1", "end": "2024-03"}`:    "2024-01->2024-03",
+		`"2024-01-05->2024-1-31"`:                  "2024-01-05->2024-01-31",
+	}
+
+	for in, expected := range cases {
+		tp := &TimePeriod{}
+		if err := json.Unmarshal([]byte(in), tp); err != nil {
+			t.Errorf("failed to decode %s: %v", in, err)
+			continue
+		}
+
+		if tp.String() != expected {
+			t.Errorf("%s: got %q, wanted %q", in, tp.String(), expected)
+		}
+	}
+
+	errCases := map[string]string{
+		`{"start": "2024/01/05", "end": "2024-02-01"}`: "start",
+		`{"start": "2024-01-05", "end": "tomorrow"}`:   "end",
+		`{"start": "2024-02-05", "end": "2024-01-01"}`: "after",
+		`"2024-01-05"`: "start->end",
+	}
+
+	for in, keyword := range errCases {
+		tp := &TimePeriod{}
+		err := json.Unmarshal([]byte(in), tp)
+		if err == nil || !strings.Contains(err.Error(), keyword) {
+			t.Errorf("%s: got %v, wanted error mentioning %q", in, err, keyword)
+		}
+	}
+}
+
+func TestTimePeriodUnmarshalYAML(t *testing.T) {
+	cases := map[string]TimePeriod{
+		"period: 2024-1-1->2024-1-2\n":                    {Start: "2024-01-01", End: "2024-01-02"},
+		"period:\n  start: 2024-1-5\n  end: 2024-02-01\n": {Start: "2024-01-05", End: "2024-02-01"},
+		"period: null\n":                                  {},
+	}
+
+	for in, expected := range cases {
+		res := struct {
+			Period TimePeriod `yaml:"period"`
+		}{}
+
+		if err := yaml.Unmarshal([]byte(in), &res); err != nil {
+			t.Errorf("failed to unmarshal %q: %v", in, err)
+			continue
+		}
+
+		if res.Period != expected {
+			t.Errorf("got %+v, wanted %+v", res.Period, expected)
+		}
+	}
+
+	res := struct {
+		Period TimePeriod `yaml:"period"`
+	}{}
+
+	for _, in := range []string{"period: 2024-02-01->2024-01-01\n", "period:\n  start: bad\n  end: 2024-01-01\n", "period: [1]\n"} {
+		if err := yaml.Unmarshal([]byte(in), &res); err == nil {
+			t.Errorf("got nil, wanted error for %q", in)
+		}
+	}
+}
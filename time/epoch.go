@@ -0,0 +1,162 @@
+/*
+ * Copyright (c) 2022 The Mof Authors
+ */
+
+package ptime
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EpochUnit unit of epoch timestamp
+type EpochUnit int
+
+const (
+	// EpochAuto detect unit by magnitude of timestamp
+	EpochAuto EpochUnit = iota
+	EpochSeconds
+	EpochMilliseconds
+	EpochMicroseconds
+	EpochNanoseconds
+)
+
+// unitsPerSecond how many units in one second
+var unitsPerSecond = map[EpochUnit]int64{
+	EpochSeconds:      1,
+	EpochMilliseconds: 1e3,
+	EpochMicroseconds: 1e6,
+	EpochNanoseconds:  1e9,
+}
+
+func (u EpochUnit) String() string {
+	switch u {
+	case EpochAuto:
+		return "auto"
+	case EpochSeconds:
+		return "s"
+	case EpochMilliseconds:
+		return "ms"
+	case EpochMicroseconds:
+		return "us"
+	case EpochNanoseconds:
+		return "ns"
+	}
+
+	return fmt.Sprintf("EpochUnit(%d)", int(u))
+}
+
+// DetectEpochUnit guess unit of epoch timestamp by magnitude
+//
+// The integer part is treated as
+// seconds:      less than 1e11, before year 5138
+// milliseconds: less than 1e14
+// microseconds: less than 1e17
+// nanoseconds:  otherwise
+func DetectEpochUnit(epoch int64) EpochUnit {
+	if epoch < 0 {
+		epoch = -epoch
+	}
+
+	switch {
+	case epoch < 1e11:
+		return EpochSeconds
+	case epoch < 1e14:
+		return EpochMilliseconds
+	case epoch < 1e17:
+		return EpochMicroseconds
+	}
+
+	return EpochNanoseconds
+}
+
+// IsEpoch checks whether incoming string looks like an epoch timestamp, like 1700000000 or 1700000000.123
+func IsEpoch(str string) bool {
+	_, _, _, err := splitEpoch(str)
+	return err == nil
+}
+
+// ParseEpoch parse epoch timestamp in given unit, fractional part is accepted
+//
+// Unit is detected by magnitude if EpochAuto is provided, see DetectEpochUnit.
+// Like time.Unix, the result is in local time zone.
+func ParseEpoch(str string, unit EpochUnit) (time.Time, error) {
+	negative, intPart, fracPart, err := splitEpoch(str)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	num, err := strconv.ParseInt(intPart, 10, 64)
+	if err != nil {
+		return time.Time{}, errors.New(fmt.Sprintf("invalid epoch timestamp: %s, %s", str, err.Error()))
+	}
+
+	if unit == EpochAuto {
+		unit = DetectEpochUnit(num)
+	}
+
+	perSecond, ok := unitsPerSecond[unit]
+	if !ok {
+		return time.Time{}, errors.New(fmt.Sprintf("invalid epoch unit: %s", unit.String()))
+	}
+
+	nanosPerUnit := int64(1e9) / perSecond
+
+	// keep digits of fractional part which are meaningful in nanoseconds
+	digits := len(strconv.FormatInt(nanosPerUnit, 10)) - 1
+	if len(fracPart) > digits {
+		fracPart = fracPart[:digits]
+	}
+	fracPart = fracPart + strings.Repeat("0", digits-len(fracPart))
+
+	var fracNanos int64
+	if len(fracPart) > 0 {
+		fracNanos, _ = strconv.ParseInt(fracPart, 10, 64)
+	}
+
+	sec := num / perSecond
+	nsec := (num%perSecond)*nanosPerUnit + fracNanos
+
+	if negative {
+		return time.Unix(-sec, -nsec), nil
+	}
+
+	return time.Unix(sec, nsec), nil
+}
+
+// splitEpoch split epoch timestamp into sign, integer part and fractional part
+func splitEpoch(str string) (bool, string, string, error) {
+	invalid := errors.New(fmt.Sprintf("invalid epoch timestamp: %s", str))
+
+	str = strings.TrimSpace(str)
+	negative := false
+
+	if strings.HasPrefix(str, "-") || strings.HasPrefix(str, "+") {
+		negative = str[0] == '-'
+		str = str[1:]
+	}
+
+	intPart, fracPart := str, ""
+	if i := strings.Index(str, "."); i >= 0 {
+		intPart, fracPart = str[:i], str[i+1:]
+	}
+
+	if len(intPart) < 1 || !isDigits(intPart) || !isDigits(fracPart) {
+		return false, "", "", invalid
+	}
+
+	return negative, intPart, fracPart, nil
+}
+
+func isDigits(str string) bool {
+	for _, c := range str {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,52 @@
+package ptime
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseEpoch(t *testing.T) {
+	expected := time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC)
+
+	cases := []string{
+		"1700000000",
+		"1700000000000",
+		"1700000000000000",
+		"1700000000000000000",
+	}
+
+	for _, in := range cases {
+		ts, err := ParseEpoch(in, EpochAuto)
+		if err != nil {
+			t.Errorf("failed to parse %q: %v", in, err)
+			continue
+		}
+
+		if !ts.Equal(expected) {
+			t.Errorf("%q: got %s, wanted %s", in, ts.UTC(), expected)
+		}
+	}
+
+	ts, _ := ParseEpoch("1700000000.123456", EpochAuto)
+	if !ts.Equal(expected.Add(123456 * time.Microsecond)) {
+		t.Errorf("got %s, fractional seconds are lost", ts.UTC())
+	}
+
+	ts, _ = ParseEpoch("1700000000", EpochMilliseconds)
+	if !ts.Equal(time.Date(1970, 1, 20, 16, 13, 20, 0, time.UTC)) {
+		t.Errorf("got %s, unit override is ignored", ts.UTC())
+	}
+
+	if _, err := ParseEpoch("17e8", EpochAuto); err == nil {
+		t.Errorf("expected error")
+	}
+}
+
+func TestProviderFormatFromEpoch(t *testing.T) {
+	seconds, _ := ToAlibabaFormatFromString("1700000000")
+	millis, _ := ToUCloudFormatFromString("1700000000000")
+
+	if seconds != millis {
+		t.Errorf("got %q and %q, epoch unit should be detected", seconds, millis)
+	}
+}
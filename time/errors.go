@@ -0,0 +1,77 @@
+/*
+ * Copyright (c) 2022 The Mof Authors
+ */
+
+package ptime
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Reason code of ValidationError
+type Reason string
+
+const (
+	ReasonInvalidFormat Reason = "invalid_format"
+	ReasonStartAfterEnd Reason = "start_after_end"
+	ReasonStartInFuture Reason = "start_in_future"
+	ReasonOutOfRange    Reason = "out_of_range"
+)
+
+// Sentinel errors, use errors.Is to check reason of ValidationError
+var (
+	ErrInvalidFormat = errors.New("invalid format")
+	ErrStartAfterEnd = errors.New("start is not before end")
+	ErrStartInFuture = errors.New("start is in the future")
+	ErrOutOfRange    = errors.New("out of range")
+)
+
+var reasonErrors = map[Reason]error{
+	ReasonInvalidFormat: ErrInvalidFormat,
+	ReasonStartAfterEnd: ErrStartAfterEnd,
+	ReasonStartInFuture: ErrStartInFuture,
+	ReasonOutOfRange:    ErrOutOfRange,
+}
+
+// ValidationError returned when time period, month or timestamp is invalid
+//
+// Field: which field is invalid, like start, end, month or timestamp
+// Value: the invalid value
+// Reason: machine-readable reason code
+// Message: human-readable message, built from other fields if empty
+//
+// Both errors.Is(err, ErrStartAfterEnd) and errors.As(err, &validationErr) are supported.
+type ValidationError struct {
+	Field   string
+	Value   string
+	Reason  Reason
+	Message string
+}
+
+func newValidationError(field, value string, reason Reason, message string) *ValidationError {
+	return &ValidationError{
+		Field:   field,
+		Value:   value,
+		Reason:  reason,
+		Message: message,
+	}
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Message) > 0 {
+		return e.Message
+	}
+
+	reason := string(e.Reason)
+	if err, ok := reasonErrors[e.Reason]; ok {
+		reason = err.Error()
+	}
+
+	return fmt.Sprintf("invalid %s: %q, %s", e.Field, e.Value, reason)
+}
+
+// Unwrap returns sentinel error of Reason
+func (e *ValidationError) Unwrap() error {
+	return reasonErrors[e.Reason]
+}
@@ -0,0 +1,73 @@
+package ptime
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestValidationError(t *testing.T) {
+	cases := []struct {
+		tp       *TimePeriod
+		field    string
+		sentinel error
+	}{
+		{&TimePeriod{Start: "2024/01/01", End: "2024-01-31"}, "start", ErrInvalidFormat},
+		{&TimePeriod{Start: "2024-01-01", End: "tomorrow"}, "end", ErrInvalidFormat},
+		{&TimePeriod{Start: "2024-02-01", End: "2024-01-01"}, "start", ErrStartAfterEnd},
+		{&TimePeriod{Start: "9999-01-01", End: "9999-01-31"}, "start", ErrStartInFuture},
+	}
+
+	for _, c := range cases {
+		err := c.tp.Validate()
+
+		if !errors.Is(err, c.sentinel) {
+			t.Errorf("%s: got %v, wanted %v", c.tp, err, c.sentinel)
+		}
+
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) || validationErr.Field != c.field {
+			t.Errorf("%s: got %v, wanted field %q", c.tp, err, c.field)
+		}
+	}
+
+	tp := &TimePeriod{Start: "2024-01-01", End: "2024-01-31"}
+	if _, _, err := tp.StartAndEndInMonth("2024-03"); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("got %v, wanted %v", err, ErrOutOfRange)
+	}
+
+	for _, c := range []struct {
+		tp    *TimePeriod
+		field string
+	}{
+		{&TimePeriod{Start: "bad", End: "2024-01-31"}, "start"},
+		{&TimePeriod{Start: "2024-01-01", End: "bad"}, "end"},
+	} {
+		_, _, err := c.tp.StartAndEndInMonth("2024-01")
+
+		var validationErr *ValidationError
+		if !errors.Is(err, ErrInvalidFormat) || !errors.As(err, &validationErr) || validationErr.Field != c.field {
+			t.Errorf("%s: got %v, wanted %v of %s", c.tp, err, ErrInvalidFormat, c.field)
+		}
+	}
+
+	if _, err := NewTimeRange(time.Unix(1, 0), time.Unix(0, 0)); !errors.Is(err, ErrStartAfterEnd) {
+		t.Errorf("got %v, wanted %v", err, ErrStartAfterEnd)
+	}
+
+	if _, _, err := CalcStartDayAndEndDay("2024-01", "2024-01-01", "bad"); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("got %v, wanted %v", err, ErrInvalidFormat)
+	}
+}
+
+func TestValidationErrorWithoutMessage(t *testing.T) {
+	err := &ValidationError{Field: "start", Value: "2024-13-01", Reason: ReasonInvalidFormat}
+
+	if got, wanted := err.Error(), `invalid start: "2024-13-01", invalid format`; got != wanted {
+		t.Errorf("got %q, wanted %q", got, wanted)
+	}
+
+	if !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("got %v, wanted %v", err, ErrInvalidFormat)
+	}
+}
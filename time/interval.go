@@ -0,0 +1,396 @@
+/*
+ * Copyright (c) 2022 The Mof Authors
+ */
+
+package ptime
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ************************************
+// ********* Interval algebra *********
+// ************************************
+//
+// All interval operations work at day granularity.
+//
+// TimePeriod.Start and TimePeriod.End are both inclusive, which means
+// {Start: 2024-01-01, End: 2024-01-31} covers 31 days. Internally every
+// period is converted to a half-open range [Start, End + 1 day) so that
+// adjacent periods like [01-01, 01-15] and [01-16, 01-31] can be merged
+// without special cases. Results are converted back to inclusive End.
+//
+// Month only values are accepted as well, YYYY-MM as Start means the first
+// day of month and YYYY-MM as End means the last day of month.
+
+// span half-open range of days [start, end)
+type span struct {
+	start time.Time
+	end   time.Time
+}
+
+func (s span) toTimePeriod() *TimePeriod {
+	return &TimePeriod{
+		Start: TimeToLayoutDay(s.start),
+		End:   TimeToLayoutDay(s.end.AddDate(0, 0, -1)),
+	}
+}
+
+// truncateToDay drop clock part of incoming time and move it to UTC
+func truncateToDay(ts time.Time) time.Time {
+	year, month, day := ts.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// toSpan convert TimePeriod to half-open range of days
+func (t *TimePeriod) toSpan() (span, error) {
+	if t == nil {
+		return span{}, errors.New("nil time period")
+	}
+
+	start, err := StringToTime(t.Start)
+	if err != nil {
+		return span{}, newValidationError("start", t.Start, ReasonInvalidFormat,
+			fmt.Sprintf("invalid startTime: %s, should be format YYYY-MM-DD", t.Start))
+	}
+
+	end, err := StringToTime(t.End)
+	if err != nil {
+		return span{}, newValidationError("end", t.End, ReasonInvalidFormat,
+			fmt.Sprintf("invalid endTime: %s, should be format of YYYY-MM-DD", t.End))
+	}
+
+	start = truncateToDay(start)
+	end = truncateToDay(end)
+
+	if IsStdMonthLayout(t.End) || IsXStdMonthLayout(t.End) {
+		// the whole month is included
+		end = end.AddDate(0, 1, 0)
+	} else {
+		end = end.AddDate(0, 0, 1)
+	}
+
+	if !start.Before(end) {
+		return span{}, newValidationError("start", t.Start, ReasonStartAfterEnd,
+			fmt.Sprintf("invalid startTime: %s endTime: %s, startTime is after endTime", t.Start, t.End))
+	}
+
+	return span{start: start, end: end}, nil
+}
+
+// ToEndTimeExclusive returns the first day after End, which is the exclusive end of TimePeriod
+func (t *TimePeriod) ToEndTimeExclusive() time.Time {
+	s, err := t.toSpan()
+	if err != nil {
+		return time.Time{}
+	}
+
+	return s.end
+}
+
+// Overlaps checks whether two periods share at least one day
+func (t *TimePeriod) Overlaps(other *TimePeriod) bool {
+	a, err := t.toSpan()
+	if err != nil {
+		return false
+	}
+
+	b, err := other.toSpan()
+	if err != nil {
+		return false
+	}
+
+	return a.start.Before(b.end) && b.start.Before(a.end)
+}
+
+// Contains checks whether every day of other is in current period
+func (t *TimePeriod) Contains(other *TimePeriod) bool {
+	a, err := t.toSpan()
+	if err != nil {
+		return false
+	}
+
+	b, err := other.toSpan()
+	if err != nil {
+		return false
+	}
+
+	return !b.start.Before(a.start) && !b.end.After(a.end)
+}
+
+// Intersect returns days shared by both periods
+//
+// nil will be returned if two periods do not overlap
+func (t *TimePeriod) Intersect(other *TimePeriod) (*TimePeriod, error) {
+	a, err := t.toSpan()
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := other.toSpan()
+	if err != nil {
+		return nil, err
+	}
+
+	res, ok := intersectSpan(a, b)
+	if !ok {
+		return nil, nil
+	}
+
+	return res.toTimePeriod(), nil
+}
+
+// Union returns days in either of two periods
+func (t *TimePeriod) Union(other *TimePeriod) (*TimePeriodSet, error) {
+	return NewTimePeriodSet(t, other)
+}
+
+// Subtract returns days in current period but not in other
+func (t *TimePeriod) Subtract(other *TimePeriod) (*TimePeriodSet, error) {
+	a, err := NewTimePeriodSet(t)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := NewTimePeriodSet(other)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.Subtract(b), nil
+}
+
+func intersectSpan(a, b span) (span, bool) {
+	res := span{start: a.start, end: a.end}
+
+	if b.start.After(res.start) {
+		res.start = b.start
+	}
+
+	if b.end.Before(res.end) {
+		res.end = b.end
+	}
+
+	if !res.start.Before(res.end) {
+		return span{}, false
+	}
+
+	return res, true
+}
+
+// ***************************************
+// ************ TimePeriodSet ************
+// ***************************************
+
+// TimePeriodSet a normalized set of days
+//
+// Periods in set are always sorted, never overlap and never adjacent,
+// overlapping or adjacent periods are merged once added.
+type TimePeriodSet struct {
+	spans []span
+}
+
+// NewTimePeriodSet create a normalized set from time periods
+func NewTimePeriodSet(periods ...*TimePeriod) (*TimePeriodSet, error) {
+	res := &TimePeriodSet{}
+
+	for i := range periods {
+		if err := res.Add(periods[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// Add merge time period into set
+func (s *TimePeriodSet) Add(tp *TimePeriod) error {
+	sp, err := tp.toSpan()
+	if err != nil {
+		return err
+	}
+
+	s.spans = normalizeSpans(append(s.spans, sp))
+	return nil
+}
+
+// Periods returns a copy of periods in set, sorted by Start
+func (s *TimePeriodSet) Periods() []*TimePeriod {
+	res := make([]*TimePeriod, 0, len(s.spans))
+
+	for i := range s.spans {
+		res = append(res, s.spans[i].toTimePeriod())
+	}
+
+	return res
+}
+
+// IsEmpty checks whether there is any day in set
+func (s *TimePeriodSet) IsEmpty() bool {
+	return len(s.spans) < 1
+}
+
+// NumOfDays how many days in set
+func (s *TimePeriodSet) NumOfDays() int {
+	res := 0
+
+	for i := range s.spans {
+		res += int(s.spans[i].end.Sub(s.spans[i].start).Hours() / 24)
+	}
+
+	return res
+}
+
+// InRange is current timestamp in any of periods?
+func (s *TimePeriodSet) InRange(ts string) bool {
+	v, err := StringToTime(ts)
+	if err != nil {
+		return false
+	}
+
+	v = truncateToDay(v)
+	for i := range s.spans {
+		if !v.Before(s.spans[i].start) && v.Before(s.spans[i].end) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Contains checks whether every day of time period is in set
+func (s *TimePeriodSet) Contains(tp *TimePeriod) bool {
+	sp, err := tp.toSpan()
+	if err != nil {
+		return false
+	}
+
+	for i := range s.spans {
+		if !sp.start.Before(s.spans[i].start) && !sp.end.After(s.spans[i].end) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Union returns days in either of two sets
+func (s *TimePeriodSet) Union(other *TimePeriodSet) *TimePeriodSet {
+	spans := make([]span, 0, len(s.spans)+len(other.spans))
+	spans = append(spans, s.spans...)
+	spans = append(spans, other.spans...)
+
+	return &TimePeriodSet{spans: normalizeSpans(spans)}
+}
+
+// Intersect returns days in both of two sets
+func (s *TimePeriodSet) Intersect(other *TimePeriodSet) *TimePeriodSet {
+	res := make([]span, 0)
+
+	for i := range s.spans {
+		for j := range other.spans {
+			if sp, ok := intersectSpan(s.spans[i], other.spans[j]); ok {
+				res = append(res, sp)
+			}
+		}
+	}
+
+	return &TimePeriodSet{spans: normalizeSpans(res)}
+}
+
+// Subtract returns days in current set but not in other
+func (s *TimePeriodSet) Subtract(other *TimePeriodSet) *TimePeriodSet {
+	res := make([]span, 0)
+
+	for i := range s.spans {
+		remain := []span{s.spans[i]}
+
+		for j := range other.spans {
+			next := make([]span, 0, len(remain))
+			for k := range remain {
+				next = append(next, subtractSpan(remain[k], other.spans[j])...)
+			}
+			remain = next
+		}
+
+		res = append(res, remain...)
+	}
+
+	return &TimePeriodSet{spans: normalizeSpans(res)}
+}
+
+// Gaps returns days in within but not covered by set
+//
+// This is useful to find days missing from collected data, for example:
+// set:    [01-01, 01-10], [01-15, 01-31]
+// within: [01-01, 02-05]
+// gaps:   [01-11, 01-14], [02-01, 02-05]
+func (s *TimePeriodSet) Gaps(within *TimePeriod) (*TimePeriodSet, error) {
+	w, err := NewTimePeriodSet(within)
+	if err != nil {
+		return nil, err
+	}
+
+	return w.Subtract(s), nil
+}
+
+func (s *TimePeriodSet) String() string {
+	res := make([]string, 0, len(s.spans))
+
+	for i := range s.spans {
+		res = append(res, s.spans[i].toTimePeriod().String())
+	}
+
+	return fmt.Sprintf("[%s]", strings.Join(res, ", "))
+}
+
+// subtractSpan returns parts of a which are not in b
+func subtractSpan(a, b span) []span {
+	if !a.start.Before(b.end) || !b.start.Before(a.end) {
+		// no overlap
+		return []span{a}
+	}
+
+	res := make([]span, 0, 2)
+
+	if a.start.Before(b.start) {
+		res = append(res, span{start: a.start, end: b.start})
+	}
+
+	if b.end.Before(a.end) {
+		res = append(res, span{start: b.end, end: a.end})
+	}
+
+	return res
+}
+
+// normalizeSpans sort spans and merge overlapping or adjacent ones
+func normalizeSpans(spans []span) []span {
+	if len(spans) < 1 {
+		return []span{}
+	}
+
+	sort.Slice(spans, func(i, j int) bool {
+		return spans[i].start.Before(spans[j].start)
+	})
+
+	res := []span{spans[0]}
+	for i := 1; i < len(spans); i++ {
+		last := &res[len(res)-1]
+
+		if !spans[i].start.After(last.end) {
+			if spans[i].end.After(last.end) {
+				last.end = spans[i].end
+			}
+			continue
+		}
+
+		res = append(res, spans[i])
+	}
+
+	return res
+}
@@ -0,0 +1,92 @@
+package ptime
+
+import (
+	"testing"
+)
+
+func TestTimePeriodIntersect(t *testing.T) {
+	a := &TimePeriod{Start: "2024-01-01", End: "2024-03-15"}
+	b := &TimePeriod{Start: "2024-02", End: "2024-05"}
+
+	res, err := a.Intersect(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "2024-02-01->2024-03-15"
+	if res.String() != expected {
+		t.Errorf("got %q, wanted %q", res.String(), expected)
+	}
+
+	res, _ = a.Intersect(&TimePeriod{Start: "2024-03-16", End: "2024-03-20"})
+	if res != nil {
+		t.Errorf("got %q, wanted nil", res.String())
+	}
+}
+
+func TestTimePeriodUnionAndSubtract(t *testing.T) {
+	a := &TimePeriod{Start: "2024-01-01", End: "2024-01-15"}
+	b := &TimePeriod{Start: "2024-01-16", End: "2024-01-31"}
+
+	union, err := a.Union(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "[2024-01-01->2024-01-31]"
+	if union.String() != expected {
+		t.Errorf("got %q, wanted %q", union.String(), expected)
+	}
+
+	month := &TimePeriod{Start: "2024-01-01", End: "2024-01-31"}
+	diff, err := month.Subtract(&TimePeriod{Start: "2024-01-10", End: "2024-01-20"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected = "[2024-01-01->2024-01-09, 2024-01-21->2024-01-31]"
+	if diff.String() != expected {
+		t.Errorf("got %q, wanted %q", diff.String(), expected)
+	}
+
+	if diff.NumOfDays() != 20 {
+		t.Errorf("got %d, wanted %d", diff.NumOfDays(), 20)
+	}
+}
+
+func TestTimePeriodOverlapsAndContains(t *testing.T) {
+	a := &TimePeriod{Start: "2024-01-01", End: "2024-01-31"}
+
+	if !a.Overlaps(&TimePeriod{Start: "2024-01-31", End: "2024-02-10"}) {
+		t.Errorf("inclusive end should overlap")
+	}
+
+	if a.Overlaps(&TimePeriod{Start: "2024-02-01", End: "2024-02-10"}) {
+		t.Errorf("adjacent periods should not overlap")
+	}
+
+	if !a.Contains(&TimePeriod{Start: "2024-01", End: "2024-01"}) {
+		t.Errorf("month should be contained")
+	}
+}
+
+func TestTimePeriodSetGaps(t *testing.T) {
+	set, err := NewTimePeriodSet(
+		&TimePeriod{Start: "2024-01-15", End: "2024-01-31"},
+		&TimePeriod{Start: "2024-01-01", End: "2024-01-10"},
+		&TimePeriod{Start: "2024-01-05", End: "2024-01-08"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gaps, err := set.Gaps(&TimePeriod{Start: "2024-01-01", End: "2024-02-05"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "[2024-01-11->2024-01-14, 2024-02-01->2024-02-05]"
+	if gaps.String() != expected {
+		t.Errorf("got %q, wanted %q", gaps.String(), expected)
+	}
+}
@@ -0,0 +1,76 @@
+/*
+ * Copyright (c) 2022 The Mof Authors
+ */
+
+package ptime
+
+import (
+	"time"
+)
+
+// MonthPolicy decide what happens if the day does not exist in target month
+type MonthPolicy int
+
+const (
+	// MonthClamp clamp to last day of target month
+	//
+	// 2024-01-31 + 1 month => 2024-02-29, 2024-02-29 + 1 month => 2024-03-29
+	MonthClamp MonthPolicy = iota
+	// MonthOverflow overflow to next month, same as time.AddDate
+	//
+	// 2024-01-31 + 1 month => 2024-03-02, 2024-02-29 + 1 month => 2024-03-29
+	MonthOverflow
+	// MonthEndSticky last day of month stays at last day of month, other days are clamped
+	//
+	// 2024-01-31 + 1 month => 2024-02-29, 2024-02-29 + 1 month => 2024-03-31
+	MonthEndSticky
+)
+
+// AddMonths shift ts by months following policy, clock and location of ts are kept
+func AddMonths(ts time.Time, months int, policy MonthPolicy) time.Time {
+	if policy == MonthOverflow {
+		return ts.AddDate(0, months, 0)
+	}
+
+	first := time.Date(ts.Year(), ts.Month()+time.Month(months), 1, 0, 0, 0, 0, ts.Location())
+	lastDay := DaysInMonthOf(first)
+
+	day := ts.Day()
+	if day > lastDay || (policy == MonthEndSticky && IsLastDayOfMonth(ts)) {
+		day = lastDay
+	}
+
+	return time.Date(first.Year(), first.Month(), day,
+		ts.Hour(), ts.Minute(), ts.Second(), ts.Nanosecond(), ts.Location())
+}
+
+// DaysInMonthOf returns number of days in month of ts, leap years are considered
+func DaysInMonthOf(ts time.Time) int {
+	return time.Date(ts.Year(), ts.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// IsLastDayOfMonth checks whether ts is the last day of its month
+func IsLastDayOfMonth(ts time.Time) bool {
+	return ts.Day() == DaysInMonthOf(ts)
+}
+
+// NaturalMonthSources returns days in the month which is monthsAgo before ts,
+// and which land on the same day as ts after shifting monthsAgo months with policy
+//
+// With MonthClamp, days of the earlier month are split among days of ts's month without
+// overlap, for example:
+// 2023-03-28 => [2023-02-28], 2023-03-31 => [], 2023-02-28 => [2023-01-28 ... 2023-01-31]
+func NaturalMonthSources(ts time.Time, monthsAgo int, policy MonthPolicy) []time.Time {
+	res := make([]time.Time, 0)
+
+	day := truncateToDay(ts)
+	first := time.Date(day.Year(), day.Month()-time.Month(monthsAgo), 1, 0, 0, 0, 0, time.UTC)
+
+	for curr := first; curr.Month() == first.Month(); curr = curr.AddDate(0, 0, 1) {
+		if AddMonths(curr, monthsAgo, policy).Equal(day) {
+			res = append(res, curr)
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,151 @@
+package ptime
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAddMonthsMonthEnd(t *testing.T) {
+	cases := []struct {
+		day      string
+		months   int
+		policy   MonthPolicy
+		expected string
+	}{
+		{"2024-01-31", 1, MonthClamp, "2024-02-29"},
+		{"2023-01-31", 1, MonthClamp, "2023-02-28"},
+		{"2024-01-31", 1, MonthOverflow, "2024-03-02"},
+		{"2023-01-31", 1, MonthOverflow, "2023-03-03"},
+		{"2024-01-31", 1, MonthEndSticky, "2024-02-29"},
+		{"2024-02-29", 1, MonthClamp, "2024-03-29"},
+		{"2024-02-29", 1, MonthEndSticky, "2024-03-31"},
+		{"2023-02-28", 12, MonthEndSticky, "2024-02-29"},
+		{"2024-02-28", 12, MonthEndSticky, "2025-02-28"},
+		{"2024-02-29", 12, MonthClamp, "2025-02-28"},
+		{"2024-02-29", 12, MonthOverflow, "2025-03-01"},
+		{"2024-02-29", 48, MonthClamp, "2028-02-29"},
+		{"2024-03-31", -1, MonthClamp, "2024-02-29"},
+		{"2024-04-30", -1, MonthEndSticky, "2024-03-31"},
+		{"2024-04-30", -1, MonthClamp, "2024-03-30"},
+		{"2024-12-31", 2, MonthClamp, "2025-02-28"},
+		{"2024-01-15", -13, MonthEndSticky, "2022-12-15"},
+	}
+
+	for _, c := range cases {
+		ts, _ := StringToTime(c.day)
+		got := TimeToLayoutDay(AddMonths(ts, c.months, c.policy))
+
+		if got != c.expected {
+			t.Errorf("%s %+d months with policy %d: got %q, wanted %q", c.day, c.months, c.policy, got, c.expected)
+		}
+	}
+}
+
+// TestAddMonthsEveryDay checks every day of every month of a common year and a leap year
+func TestAddMonthsEveryDay(t *testing.T) {
+	for _, year := range []int{2023, 2024} {
+		for day := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC); day.Year() == year; day = day.AddDate(0, 0, 1) {
+			for months := -24; months <= 24; months++ {
+				expectedMonth := time.Date(year, day.Month()+time.Month(months), 1, 0, 0, 0, 0, time.UTC)
+				lastDay := DaysInMonthOf(expectedMonth)
+
+				clamp := AddMonths(day, months, MonthClamp)
+				sticky := AddMonths(day, months, MonthEndSticky)
+				overflow := AddMonths(day, months, MonthOverflow)
+
+				if clamp.Year() != expectedMonth.Year() || clamp.Month() != expectedMonth.Month() {
+					t.Fatalf("%s %+d: got %s, wanted month %s", TimeToLayoutDay(day), months,
+						TimeToLayoutDay(clamp), TimeToLayoutMonth(expectedMonth))
+				}
+
+				expectedDay := day.Day()
+				if expectedDay > lastDay {
+					expectedDay = lastDay
+				}
+
+				if clamp.Day() != expectedDay {
+					t.Fatalf("%s %+d: got %s with MonthClamp", TimeToLayoutDay(day), months, TimeToLayoutDay(clamp))
+				}
+
+				if IsLastDayOfMonth(day) {
+					expectedDay = lastDay
+				}
+
+				if sticky.Day() != expectedDay || sticky.Month() != expectedMonth.Month() {
+					t.Fatalf("%s %+d: got %s with MonthEndSticky", TimeToLayoutDay(day), months, TimeToLayoutDay(sticky))
+				}
+
+				if !overflow.Equal(day.AddDate(0, months, 0)) {
+					t.Fatalf("%s %+d: got %s with MonthOverflow", TimeToLayoutDay(day), months, TimeToLayoutDay(overflow))
+				}
+			}
+		}
+	}
+}
+
+func TestListDateForNaturalMonthSet(t *testing.T) {
+	cases := map[string]string{
+		"2023-03-15": "2023-02-15",
+		"2023-03-28": "2023-02-28",
+		"2023-03-29": "",
+		"2023-03-31": "",
+		"2023-02-27": "2023-01-27",
+		"2023-02-28": "2023-01-28,2023-01-29,2023-01-30,2023-01-31",
+		"2024-02-28": "2024-01-28",
+		"2024-02-29": "2024-01-29,2024-01-30,2024-01-31",
+		"2024-04-30": "2024-03-30,2024-03-31",
+		"2024-05-30": "2024-04-30",
+		"2024-05-31": "",
+		"2024-01-31": "2023-12-31",
+	}
+
+	for day, expected := range cases {
+		got := strings.Join(ListDateForNaturalMonthSet(day, 1), ",")
+		if got != expected {
+			t.Errorf("%s: got %q, wanted %q", day, got, expected)
+		}
+	}
+
+	got := strings.Join(ListDateForNaturalMonth("2024-03-31", 2), ",")
+	if got != "2024-01-31" {
+		t.Errorf("got %q, wanted %q", got, "2024-01-31")
+	}
+
+	got = strings.Join(ListDateForNaturalMonthSet("2024-02-29", 12), ",")
+	if !strings.HasSuffix(got, "2023-03-29,2023-03-30,2023-03-31") {
+		t.Errorf("got %q, 2023-02 has no day 29", got)
+	}
+}
+
+// TestListDateForNaturalMonthSetPartition checks for every month of a common year and a leap year,
+// and up to 24 months back, each day of earlier month belongs to exactly one day of current month
+func TestListDateForNaturalMonthSetPartition(t *testing.T) {
+	for _, year := range []int{2023, 2024} {
+		for month := time.January; month <= time.December; month++ {
+			first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+
+			for monthsAgo := 1; monthsAgo <= 24; monthsAgo++ {
+				seen := map[string]int{}
+
+				for day := first; day.Month() == month; day = day.AddDate(0, 0, 1) {
+					for _, src := range NaturalMonthSources(day, monthsAgo, MonthClamp) {
+						seen[TimeToLayoutDay(src)]++
+					}
+				}
+
+				earlier := AddMonths(first, -monthsAgo, MonthClamp)
+				if len(seen) != DaysInMonthOf(earlier) {
+					t.Fatalf("%s, %d months ago: got %d days, wanted %d", TimeToLayoutMonth(first), monthsAgo,
+						len(seen), DaysInMonthOf(earlier))
+				}
+
+				for k, v := range seen {
+					if v != 1 || !strings.HasPrefix(k, TimeToLayoutMonth(earlier)) {
+						t.Fatalf("%s, %d months ago: %s is collected %d times", TimeToLayoutMonth(first), monthsAgo, k, v)
+					}
+				}
+			}
+		}
+	}
+}
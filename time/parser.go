@@ -0,0 +1,171 @@
+/*
+ * Copyright (c) 2022 The Mof Authors
+ */
+
+package ptime
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Layout named time layout
+type Layout struct {
+	Name  string
+	Value string
+}
+
+// defaultLayouts the order matters, lenient Parser picks the first one that fits
+var defaultLayouts = []Layout{
+	{Name: "YYYY-MM-DD", Value: "2006-01-02"},
+	{Name: "YYYY-M-D", Value: "2006-1-2"},
+	{Name: "YYYY-MM", Value: "2006-01"},
+	{Name: "YYYY-M", Value: "2006-1"},
+	{Name: "YYYY-MM-DDTHH:MMZ", Value: "2006-01-02T15:04Z"},
+	{Name: "Prophet", Value: ProphetFormat},
+	{Name: "AwsCloudWatch", Value: AwsCloudWatchFormat},
+	{Name: "AwsCloudWatchNumericZone", Value: AwsCloudWatchNumericZoneFormat},
+	{Name: "Linode", Value: LinodeFormat},
+	{Name: "ANSIC", Value: time.ANSIC},
+	{Name: "UnixDate", Value: time.UnixDate},
+	{Name: "RubyDate", Value: time.RubyDate},
+	{Name: "RFC822", Value: time.RFC822},
+	{Name: "RFC822Z", Value: time.RFC822Z},
+	{Name: "RFC850", Value: time.RFC850},
+	{Name: "RFC1123", Value: time.RFC1123},
+	{Name: "RFC1123Z", Value: time.RFC1123Z},
+	{Name: "RFC3339", Value: time.RFC3339},
+	{Name: "RFC3339Nano", Value: time.RFC3339Nano},
+	{Name: "Kitchen", Value: time.Kitchen},
+	{Name: "Stamp", Value: time.Stamp},
+	{Name: "StampMilli", Value: time.StampMilli},
+	{Name: "StampMicro", Value: time.StampMicro},
+	{Name: "StampNano", Value: time.StampNano},
+}
+
+// defaultParser used by StringToTime, input is used as it is like before Parser was introduced
+var defaultParser = &Parser{
+	Layouts:  DefaultLayouts(),
+	Location: time.UTC,
+}
+
+// DefaultLayouts returns a copy of layouts used by StringToTime
+func DefaultLayouts() []Layout {
+	res := make([]Layout, len(defaultLayouts))
+	copy(res, defaultLayouts)
+	return res
+}
+
+// Parser parse timestamp with an ordered set of layouts
+//
+// Layouts: layouts to try, in order
+// Strict: if true, incoming string is rejected if it fits more than one layout
+// with different results. Otherwise, the first layout that fits wins.
+// Clean: if true, surrounding spaces and monotonic clock reading printed by
+// time.Time.String() are removed before parsing, also in Strict mode.
+// Location: location used for layouts without time zone, UTC if nil
+type Parser struct {
+	Layouts  []Layout
+	Strict   bool
+	Clean    bool
+	Location *time.Location
+}
+
+// NewParser create a lenient Parser with default layouts in UTC, which cleans input as well
+func NewParser() *Parser {
+	return &Parser{
+		Layouts:  DefaultLayouts(),
+		Strict:   false,
+		Clean:    true,
+		Location: time.UTC,
+	}
+}
+
+// LayoutError failed attempt of a layout
+type LayoutError struct {
+	Layout Layout
+	Err    error
+}
+
+// ParseError returned by Parser if no layout fits or input is ambiguous
+type ParseError struct {
+	Input string
+	// Tried layouts which do not fit
+	Tried []LayoutError
+	// Matched layouts which fit with different results, only set in strict mode
+	Matched []Layout
+}
+
+func (e *ParseError) Error() string {
+	if len(e.Matched) > 1 {
+		names := make([]string, 0, len(e.Matched))
+		for i := range e.Matched {
+			names = append(names, fmt.Sprintf("%s(%s)", e.Matched[i].Name, e.Matched[i].Value))
+		}
+
+		return fmt.Sprintf("ambiguous timestamp %q, matched layouts: %s", e.Input, strings.Join(names, ", "))
+	}
+
+	names := make([]string, 0, len(e.Tried))
+	for i := range e.Tried {
+		names = append(names, fmt.Sprintf("%s(%s)", e.Tried[i].Layout.Name, e.Tried[i].Layout.Value))
+	}
+
+	return fmt.Sprintf("failed to parse timestamp %q, tried layouts: %s", e.Input, strings.Join(names, ", "))
+}
+
+// Parse parse string to time and returns name of matched layout
+func (p *Parser) Parse(str string) (time.Time, string, error) {
+	loc := p.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	if p.Clean {
+		str = cleanTimestamp(str)
+	}
+
+	parseErr := &ParseError{Input: str}
+
+	var res time.Time
+	var matched *Layout
+
+	for i := range p.Layouts {
+		l := p.Layouts[i]
+
+		ts, err := time.ParseInLocation(l.Value, str, loc)
+		if err != nil {
+			parseErr.Tried = append(parseErr.Tried, LayoutError{Layout: l, Err: err})
+			continue
+		}
+
+		if !p.Strict {
+			return ts, l.Name, nil
+		}
+
+		if matched == nil {
+			res, matched = ts, &l
+			parseErr.Matched = append(parseErr.Matched, l)
+		} else if !ts.Equal(res) {
+			parseErr.Matched = append(parseErr.Matched, l)
+		}
+	}
+
+	if matched == nil || len(parseErr.Matched) > 1 {
+		return time.Time{}, "", parseErr
+	}
+
+	return res, matched.Name, nil
+}
+
+// cleanTimestamp remove surrounding spaces and monotonic clock reading like " m=+0.000058698"
+func cleanTimestamp(str string) string {
+	str = strings.TrimSpace(str)
+
+	if i := strings.Index(str, " m="); i > 0 {
+		str = str[:i]
+	}
+
+	return str
+}
@@ -0,0 +1,91 @@
+package ptime
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParserMatchedLayout(t *testing.T) {
+	p := NewParser()
+
+	cases := map[string]string{
+		"2024-01-05":                      "YYYY-MM-DD",
+		"2024-1-5":                        "YYYY-M-D",
+		"2024-01":                         "YYYY-MM",
+		"2022-01-02 03:04:05 +0800 CST":   "AwsCloudWatch",
+		"2022-01-02 03:04:05 +0800 +0800": "AwsCloudWatchNumericZone",
+		" 2022-01-02T03:04:05 ":           "Linode",
+	}
+
+	for in, expected := range cases {
+		_, name, err := p.Parse(in)
+		if err != nil {
+			t.Errorf("failed to parse %q: %v", in, err)
+			continue
+		}
+
+		if name != expected {
+			t.Errorf("%q: got %q, wanted %q", in, name, expected)
+		}
+	}
+
+	ts, _, err := p.Parse("2022-01-02 03:04:05.1 +0800 +0800 m=+0.000058698")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !ts.Equal(time.Date(2022, 1, 1, 19, 4, 5, 100000000, time.UTC)) {
+		t.Errorf("got %s, time zone is not honored", ts)
+	}
+}
+
+func TestStringToTimeDoesNotClean(t *testing.T) {
+	if _, err := StringToTime(" 2024-01-05 "); err == nil {
+		t.Errorf("got nil, wanted error for surrounding spaces")
+	}
+
+	if _, err := StringToTime("2022-01-02 03:04:05.1 +0800 +0800 m=+0.000058698"); err == nil {
+		t.Errorf("got nil, wanted error for monotonic clock reading")
+	}
+
+	p := NewParser()
+	p.Clean = false
+	if _, _, err := p.Parse(" 2024-01-05"); err == nil {
+		t.Errorf("got nil, wanted error without Clean")
+	}
+}
+
+func TestParserStrict(t *testing.T) {
+	p := &Parser{
+		Layouts: []Layout{
+			{Name: "US", Value: "01/02/2006"},
+			{Name: "EU", Value: "02/01/2006"},
+		},
+		Strict: true,
+	}
+
+	_, _, err := p.Parse("03/04/2024")
+
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || len(parseErr.Matched) != 2 {
+		t.Fatalf("got %v, wanted ambiguous error", err)
+	}
+
+	_, name, err := p.Parse("13/04/2024")
+	if err != nil || name != "EU" {
+		t.Errorf("got %q %v, wanted %q", name, err, "EU")
+	}
+
+	_, _, err = p.Parse(" 13/04/2024")
+	if err == nil || !strings.Contains(err.Error(), "US(01/02/2006), EU(02/01/2006)") {
+		t.Errorf("got %v, wanted error listing every layout", err)
+	}
+
+	p.Clean = true
+	_, name, err = p.Parse(" 13/04/2024 ")
+	if err != nil || name != "EU" {
+		t.Errorf("got %q %v, wanted %q with Clean in strict mode", name, err, "EU")
+	}
+}
@@ -0,0 +1,134 @@
+/*
+ * Copyright (c) 2022 The Mof Authors
+ */
+
+package ptime
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// ProrationMode how an amount of billing cycle is spread over days
+type ProrationMode int
+
+const (
+	// ProrateByDay split amount of each cycle evenly over days in that cycle,
+	// a day in February receives 1/28 of monthly amount while a day in March receives 1/31
+	ProrateByDay ProrationMode = iota
+	// ProrateBySecond convert amount to a constant rate per second with average length of cycle,
+	// 730 hours per month and 8760 hours per year, so every day receives the same amount
+	ProrateBySecond
+)
+
+const (
+	hoursPerMonth = 730
+	hoursPerYear  = 8760
+)
+
+// Prorate spread amount of billing cycle over days of TimePeriod
+//
+// amount: amount of one billing cycle, negative amount like credit is allowed
+// cycle: one of Hourly, Daily, Monthly and Yearly
+// mode: ProrateByDay or ProrateBySecond
+// precision: number of decimal places of each day, like 2 for cents
+//
+// Returns day(YYYY-MM-DD) -> amount. Amounts are rounded with largest remainder method,
+// so that they always sum up to the prorated total rounded to precision.
+func (t *TimePeriod) Prorate(amount float64, cycle string, mode ProrationMode, precision int) (map[string]float64, error) {
+	if precision < 0 {
+		return nil, errors.New(fmt.Sprintf("invalid precision: %d", precision))
+	}
+
+	sp, err := t.toSpan()
+	if err != nil {
+		return nil, err
+	}
+
+	days := make([]time.Time, 0)
+	shares := make([]float64, 0)
+
+	for day := sp.start; day.Before(sp.end); day = day.AddDate(0, 0, 1) {
+		share, err := dailyShare(math.Abs(amount), cycle, mode, day)
+		if err != nil {
+			return nil, err
+		}
+
+		days = append(days, day)
+		shares = append(shares, share)
+	}
+
+	units := distributeUnits(shares, math.Pow10(precision))
+
+	res := make(map[string]float64, len(days))
+	for i := range days {
+		v := float64(units[i]) / math.Pow10(precision)
+		if amount < 0 && v != 0 {
+			v = -v
+		}
+
+		res[TimeToLayoutDay(days[i])] = v
+	}
+
+	return res, nil
+}
+
+// dailyShare amount of cycle attributed to day
+func dailyShare(amount float64, cycle string, mode ProrationMode, day time.Time) (float64, error) {
+	switch cycle {
+	case Hourly:
+		return amount * 24, nil
+	case Daily:
+		return amount, nil
+	case Monthly:
+		if mode == ProrateBySecond {
+			return amount * 24 / hoursPerMonth, nil
+		}
+
+		daysInMonth := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+		return amount / float64(daysInMonth), nil
+	case Yearly:
+		if mode == ProrateBySecond {
+			return amount * 24 / hoursPerYear, nil
+		}
+
+		daysInYear := time.Date(day.Year(), time.December, 31, 0, 0, 0, 0, time.UTC).YearDay()
+		return amount / float64(daysInYear), nil
+	}
+
+	return 0, errors.New(fmt.Sprintf("invalid billing cycle: %s, should be one of %s, %s, %s, %s",
+		cycle, Hourly, Daily, Monthly, Yearly))
+}
+
+// distributeUnits convert shares to integer units of 1/scale with largest remainder method
+func distributeUnits(shares []float64, scale float64) []int64 {
+	total := 0.0
+	for i := range shares {
+		total += shares[i]
+	}
+
+	res := make([]int64, len(shares))
+	remainders := make([]int, len(shares))
+
+	left := int64(math.Round(total * scale))
+	for i := range shares {
+		res[i] = int64(math.Floor(shares[i] * scale))
+		left -= res[i]
+		remainders[i] = i
+	}
+
+	sort.SliceStable(remainders, func(i, j int) bool {
+		a, b := remainders[i], remainders[j]
+		return shares[a]*scale-float64(res[a]) > shares[b]*scale-float64(res[b])
+	})
+
+	for i := 0; left > 0 && len(remainders) > 0; i++ {
+		res[remainders[i%len(remainders)]]++
+		left--
+	}
+
+	return res
+}
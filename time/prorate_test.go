@@ -0,0 +1,51 @@
+package ptime
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTimePeriodProrate(t *testing.T) {
+	tp := &TimePeriod{Start: "2024-01-30", End: "2024-02-02"}
+
+	res, err := tp.Prorate(100, Monthly, ProrateByDay, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]float64{
+		"2024-01-30": 3.23,
+		"2024-01-31": 3.22,
+		"2024-02-01": 3.45,
+		"2024-02-02": 3.45,
+	}
+
+	for k, v := range expected {
+		if res[k] != v {
+			t.Errorf("%s: got %v, wanted %v", k, res[k], v)
+		}
+	}
+}
+
+func TestTimePeriodProrateSumsToTotal(t *testing.T) {
+	tp := &TimePeriod{Start: "2024-01-01", End: "2024-12-31"}
+
+	res, err := tp.Prorate(-1000, Yearly, ProrateBySecond, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cents := int64(0)
+	for _, v := range res {
+		cents += int64(math.Round(v * 100))
+	}
+
+	// 366 days of 1000 / 8760 hours
+	if cents != -100274 {
+		t.Errorf("got %d cents, wanted %d", cents, -100274)
+	}
+
+	if _, err := tp.Prorate(1, "weekly", ProrateByDay, 2); err == nil {
+		t.Errorf("expected error for unknown cycle")
+	}
+}
@@ -0,0 +1,271 @@
+/*
+ * Copyright (c) 2022 The Mof Authors
+ */
+
+package ptime
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	ProviderAWS     = "aws"
+	ProviderAzure   = "azure"
+	ProviderGCP     = "gcp"
+	ProviderAlibaba = "alibaba"
+	ProviderTencent = "tencent"
+	ProviderHuawei  = "huawei"
+	ProviderUCloud  = "ucloud"
+	ProviderLinode  = "linode"
+	ProviderProphet = "prophet"
+)
+
+// chinaStandardTime fixed zone, so that we don't depend on tzdata
+var chinaStandardTime = time.FixedZone("CST", 8*3600)
+
+// Provider timestamp conventions of a cloud provider
+//
+// Name: unique name of provider
+// Layouts: input layouts, in order
+// EpochUnit: unit of numeric timestamps, EpochAuto to detect by magnitude
+// Location: used for layouts without time zone and for output,
+// if nil, zone-less layouts are parsed as UTC and timestamps are formatted as they are
+// OutputFormat: layout used by Format
+type Provider struct {
+	Name         string
+	Layouts      []Layout
+	EpochUnit    EpochUnit
+	Location     *time.Location
+	OutputFormat string
+}
+
+var (
+	providerLock = sync.RWMutex{}
+	providers    = map[string]*Provider{}
+)
+
+func init() {
+	for _, p := range []*Provider{
+		{
+			Name: ProviderAWS,
+			Layouts: withDefaultLayouts(
+				Layout{Name: "RFC3339", Value: time.RFC3339},
+				Layout{Name: "AwsCloudWatch", Value: AwsCloudWatchFormat},
+				Layout{Name: "AwsCloudWatchNumericZone", Value: AwsCloudWatchNumericZoneFormat},
+			),
+			EpochUnit:    EpochAuto,
+			Location:     time.UTC,
+			OutputFormat: time.RFC3339,
+		},
+		{
+			Name: ProviderAzure,
+			Layouts: withDefaultLayouts(
+				Layout{Name: "RFC3339", Value: time.RFC3339},
+				Layout{Name: "MM/DD/YYYY", Value: "01/02/2006"},
+			),
+			EpochUnit:    EpochAuto,
+			Location:     time.UTC,
+			OutputFormat: time.RFC3339,
+		},
+		{
+			Name: ProviderGCP,
+			Layouts: withDefaultLayouts(
+				Layout{Name: "RFC3339", Value: time.RFC3339},
+				Layout{Name: "BigQuery", Value: "2006-01-02 15:04:05 MST"},
+				Layout{Name: "YYYYMM", Value: "200601"},
+			),
+			EpochUnit:    EpochAuto,
+			Location:     time.UTC,
+			OutputFormat: time.RFC3339,
+		},
+		{
+			Name:         ProviderAlibaba,
+			Layouts:      DefaultLayouts(),
+			EpochUnit:    EpochAuto,
+			OutputFormat: time.RFC3339Nano,
+		},
+		{
+			Name:         ProviderTencent,
+			Layouts:      withDefaultLayouts(),
+			EpochUnit:    EpochAuto,
+			Location:     chinaStandardTime,
+			OutputFormat: ProphetFormat,
+		},
+		{
+			Name: ProviderHuawei,
+			Layouts: withDefaultLayouts(
+				Layout{Name: "RFC3339", Value: time.RFC3339},
+			),
+			EpochUnit:    EpochAuto,
+			Location:     time.UTC,
+			OutputFormat: time.RFC3339,
+		},
+		{
+			Name:         ProviderUCloud,
+			Layouts:      DefaultLayouts(),
+			EpochUnit:    EpochAuto,
+			OutputFormat: time.RFC3339Nano,
+		},
+		{
+			Name: ProviderLinode,
+			Layouts: withDefaultLayouts(
+				Layout{Name: "Linode", Value: LinodeFormat},
+			),
+			EpochUnit:    EpochAuto,
+			Location:     time.UTC,
+			OutputFormat: LinodeFormat,
+		},
+		{
+			Name:         ProviderProphet,
+			Layouts:      DefaultLayouts(),
+			EpochUnit:    EpochAuto,
+			OutputFormat: ProphetFormat,
+		},
+	} {
+		if err := RegisterProvider(p); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// clone copy of provider, Layouts is copied as well
+func (p *Provider) clone() *Provider {
+	res := *p
+	res.Layouts = make([]Layout, len(p.Layouts))
+	copy(res.Layouts, p.Layouts)
+	return &res
+}
+
+// withDefaultLayouts provider specific layouts first, then default layouts
+func withDefaultLayouts(layouts ...Layout) []Layout {
+	return append(layouts, DefaultLayouts()...)
+}
+
+// RegisterProvider register timestamp conventions of a provider
+func RegisterProvider(p *Provider) error {
+	if p == nil || len(p.Name) < 1 {
+		return errors.New("provider name is required")
+	}
+
+	if len(p.Layouts) < 1 {
+		return errors.New(fmt.Sprintf("at least one layout is required for provider %s", p.Name))
+	}
+
+	if len(p.OutputFormat) < 1 {
+		return errors.New(fmt.Sprintf("output format is required for provider %s", p.Name))
+	}
+
+	providerLock.Lock()
+	defer providerLock.Unlock()
+
+	if _, ok := providers[p.Name]; ok {
+		return errors.New(fmt.Sprintf("provider %s is already registered", p.Name))
+	}
+
+	// keep a copy, so that the caller cannot change registered provider afterwards
+	providers[p.Name] = p.clone()
+	return nil
+}
+
+// LookupProvider get registered provider by name
+//
+// A copy is returned, changing it does not affect the registry.
+func LookupProvider(name string) (Provider, bool) {
+	providerLock.RLock()
+	defer providerLock.RUnlock()
+
+	p, ok := providers[name]
+	if !ok {
+		return Provider{}, false
+	}
+
+	return *p.clone(), true
+}
+
+// ListProviders returns sorted names of registered providers
+func ListProviders() []string {
+	providerLock.RLock()
+	defer providerLock.RUnlock()
+
+	res := make([]string, 0, len(providers))
+	for k := range providers {
+		res = append(res, k)
+	}
+
+	sort.Strings(res)
+	return res
+}
+
+// Parse parse timestamp following conventions of provider
+func Parse(provider, str string) (time.Time, error) {
+	p, ok := LookupProvider(provider)
+	if !ok {
+		return time.Time{}, errors.New(fmt.Sprintf("unknown provider: %s", provider))
+	}
+
+	return p.Parse(str)
+}
+
+// Format format timestamp following conventions of provider
+func Format(provider string, ts time.Time) (string, error) {
+	p, ok := LookupProvider(provider)
+	if !ok {
+		return "", errors.New(fmt.Sprintf("unknown provider: %s", provider))
+	}
+
+	return p.Format(ts), nil
+}
+
+// Convert parse timestamp and format it following conventions of provider
+func Convert(provider, str string) (string, error) {
+	p, ok := LookupProvider(provider)
+	if !ok {
+		return "", errors.New(fmt.Sprintf("unknown provider: %s", provider))
+	}
+
+	ts, err := p.Parse(str)
+	if err != nil {
+		return "", err
+	}
+
+	return p.Format(ts), nil
+}
+
+// Parse parse timestamp with layouts of provider, or as epoch if no layout fits
+//
+// Layouts are tried first, so that digit-only layouts like YYYYMM are not taken as epoch.
+func (p *Provider) Parse(str string) (time.Time, error) {
+	parser := &Parser{
+		Layouts:  p.Layouts,
+		Location: p.Location,
+	}
+
+	ts, _, err := parser.Parse(str)
+	if err == nil || !IsEpoch(str) {
+		return ts, err
+	}
+
+	ts, err = ParseEpoch(str, p.EpochUnit)
+	if err != nil {
+		return ts, err
+	}
+
+	if p.Location != nil {
+		ts = ts.In(p.Location)
+	}
+
+	return ts, nil
+}
+
+// Format format timestamp with output format of provider
+func (p *Provider) Format(ts time.Time) string {
+	if p.Location != nil {
+		ts = ts.In(p.Location)
+	}
+
+	return ts.Format(p.OutputFormat)
+}
@@ -0,0 +1,88 @@
+package ptime
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProviderRegistry(t *testing.T) {
+	ts, err := Parse(ProviderTencent, "2024-01-01 08:00:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !ts.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("got %s, time zone of provider is ignored", ts.UTC())
+	}
+
+	res, _ := Format(ProviderLinode, ts)
+	if res != "2024-01-01T00:00:00" {
+		t.Errorf("got %q, wanted %q", res, "2024-01-01T00:00:00")
+	}
+
+	res, _ = Convert(ProviderAzure, "01/31/2024")
+	if res != "2024-01-31T00:00:00Z" {
+		t.Errorf("got %q, wanted %q", res, "2024-01-31T00:00:00Z")
+	}
+
+	if _, err := Parse("unknown", "2024-01-01"); err == nil {
+		t.Errorf("expected error for unknown provider")
+	}
+
+	ts, err = Parse(ProviderGCP, "202401")
+	if err != nil || !ts.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("got %s %v, wanted layout YYYYMM to win over epoch", ts, err)
+	}
+
+	ts, err = Parse(ProviderGCP, "1704067200")
+	if err != nil || !ts.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("got %s %v, wanted epoch", ts, err)
+	}
+}
+
+// unregisterProvider remove provider registered by test
+func unregisterProvider(name string) {
+	providerLock.Lock()
+	defer providerLock.Unlock()
+
+	delete(providers, name)
+}
+
+func TestRegisterProvider(t *testing.T) {
+	t.Cleanup(func() {
+		unregisterProvider("test-provider")
+	})
+
+	err := RegisterProvider(&Provider{
+		Name:         "test-provider",
+		Layouts:      []Layout{{Name: "YYYY/MM/DD", Value: "2006/01/02"}},
+		OutputFormat: "20060102",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := Convert("test-provider", "2024/02/03")
+	if err != nil || res != "20240203" {
+		t.Errorf("got %q %v, wanted %q", res, err, "20240203")
+	}
+
+	if err := RegisterProvider(&Provider{Name: ProviderAWS, Layouts: DefaultLayouts(), OutputFormat: time.RFC3339}); err == nil {
+		t.Errorf("expected error for duplicated provider")
+	}
+}
+
+func TestLookupProviderReturnsCopy(t *testing.T) {
+	p, ok := LookupProvider(ProviderAWS)
+	if !ok {
+		t.Fatalf("provider %s is not registered", ProviderAWS)
+	}
+
+	p.OutputFormat = "changed"
+	p.Layouts[0] = Layout{Name: "changed", Value: "changed"}
+
+	again, _ := LookupProvider(ProviderAWS)
+	if again.OutputFormat == "changed" || again.Layouts[0].Name == "changed" {
+		t.Errorf("got %+v, wanted registry untouched", again)
+	}
+}
@@ -0,0 +1,445 @@
+/*
+ * Copyright (c) 2022 The Mof Authors
+ */
+
+package ptime
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PeriodUnit unit of relative period expressions
+type PeriodUnit string
+
+const (
+	UnitDay     PeriodUnit = "day"
+	UnitWeek    PeriodUnit = "week"
+	UnitMonth   PeriodUnit = "month"
+	UnitQuarter PeriodUnit = "quarter"
+	UnitYear    PeriodUnit = "year"
+)
+
+type relativeKind int
+
+const (
+	relativeToday relativeKind = iota
+	relativeYesterday
+	relativeLast
+	relativeLastFull
+	relativePrevious
+	relativeThis
+	relativeToDate
+	relativeInterval
+)
+
+// toDateAlias maps XTD style expressions to unit
+var toDateAlias = map[string]PeriodUnit{
+	"wtd": UnitWeek,
+	"mtd": UnitMonth,
+	"qtd": UnitQuarter,
+	"ytd": UnitYear,
+}
+
+// RelativePeriod a parsed relative period expression
+//
+// Supported expressions, case-insensitive:
+//
+//	today, yesterday
+//	last N days|weeks|months|quarters|years       rolling window which ends on reference day
+//	last N full days|weeks|months|quarters|years  N complete units before the one contains reference day
+//	previous|last day|week|month|quarter|year     same as last 1 full unit
+//	this week|month|quarter|year                  the whole unit contains reference day
+//	wtd, mtd, qtd, ytd                            from start of unit to reference day
+//	2024-01-01/P3M, P3M/2024-03-31, 2024-01-01/2024-03-31   ISO 8601 intervals
+//
+// Weeks start on Monday. End of the resolved TimePeriod is inclusive, so
+// "2024-01-01/P3M" resolves to 2024-01-01->2024-03-31.
+//
+// String() returns the canonical form which can be parsed back by ParseRelativePeriod.
+type RelativePeriod struct {
+	kind  relativeKind
+	unit  PeriodUnit
+	count int
+
+	// only used by ISO 8601 intervals
+	start    time.Time
+	end      time.Time
+	duration *isoDuration
+}
+
+// ResolveRelativePeriod parse expression and resolve it relative to ref
+func ResolveRelativePeriod(expr string, ref time.Time) (*TimePeriod, error) {
+	rp, err := ParseRelativePeriod(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	return rp.Resolve(ref), nil
+}
+
+// ParseRelativePeriod parse relative period expression
+func ParseRelativePeriod(expr string) (*RelativePeriod, error) {
+	raw := strings.TrimSpace(expr)
+	if strings.Contains(raw, "/") {
+		return parseISOInterval(raw)
+	}
+
+	tokens := strings.Fields(strings.ToLower(raw))
+	invalid := errors.New(fmt.Sprintf("invalid relative period expression: %q", expr))
+
+	if len(tokens) < 1 {
+		return nil, invalid
+	}
+
+	switch len(tokens) {
+	case 1:
+		switch tokens[0] {
+		case "today":
+			return &RelativePeriod{kind: relativeToday}, nil
+		case "yesterday":
+			return &RelativePeriod{kind: relativeYesterday}, nil
+		}
+
+		if unit, ok := toDateAlias[tokens[0]]; ok {
+			return &RelativePeriod{kind: relativeToDate, unit: unit}, nil
+		}
+	case 2:
+		unit, ok := parsePeriodUnit(tokens[1], false)
+		if !ok {
+			return nil, invalid
+		}
+
+		switch tokens[0] {
+		case "this":
+			return &RelativePeriod{kind: relativeThis, unit: unit}, nil
+		case "previous", "prev", "last":
+			return &RelativePeriod{kind: relativePrevious, unit: unit}, nil
+		}
+	case 3, 4:
+		if tokens[0] != "last" && tokens[0] != "past" {
+			return nil, invalid
+		}
+
+		// last full month
+		if len(tokens) == 3 && tokens[1] == "full" {
+			if unit, ok := parsePeriodUnit(tokens[2], false); ok {
+				return &RelativePeriod{kind: relativeLastFull, unit: unit, count: 1}, nil
+			}
+			return nil, invalid
+		}
+
+		count, err := strconv.Atoi(tokens[1])
+		if err != nil || count < 1 {
+			return nil, invalid
+		}
+
+		kind := relativeLast
+		unitToken := tokens[2]
+		if len(tokens) == 4 {
+			if tokens[2] != "full" {
+				return nil, invalid
+			}
+			kind = relativeLastFull
+			unitToken = tokens[3]
+		}
+
+		unit, ok := parsePeriodUnit(unitToken, true)
+		if !ok {
+			return nil, invalid
+		}
+
+		return &RelativePeriod{kind: kind, unit: unit, count: count}, nil
+	}
+
+	return nil, invalid
+}
+
+// Resolve convert relative period to TimePeriod based on reference time
+//
+// Only the date part of ref in its own location is used.
+func (r *RelativePeriod) Resolve(ref time.Time) *TimePeriod {
+	year, month, day := ref.Date()
+	today := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+
+	var start, end time.Time
+
+	switch r.kind {
+	case relativeToday:
+		start, end = today, today
+	case relativeYesterday:
+		start = today.AddDate(0, 0, -1)
+		end = start
+	case relativeLast:
+		start = addPeriodUnits(today, r.unit, -r.count).AddDate(0, 0, 1)
+		end = today
+	case relativeLastFull:
+		curr := unitStart(today, r.unit)
+		start = addPeriodUnits(curr, r.unit, -r.count)
+		end = curr.AddDate(0, 0, -1)
+	case relativePrevious:
+		curr := unitStart(today, r.unit)
+		start = addPeriodUnits(curr, r.unit, -1)
+		end = curr.AddDate(0, 0, -1)
+	case relativeThis:
+		start = unitStart(today, r.unit)
+		end = addPeriodUnits(start, r.unit, 1).AddDate(0, 0, -1)
+	case relativeToDate:
+		start = unitStart(today, r.unit)
+		end = today
+	case relativeInterval:
+		start, end = r.start, r.end
+
+		if r.duration != nil && end.IsZero() {
+			end = r.duration.addTo(start, 1).AddDate(0, 0, -1)
+		} else if r.duration != nil && start.IsZero() {
+			start = r.duration.addTo(end.AddDate(0, 0, 1), -1)
+		}
+	}
+
+	return &TimePeriod{
+		Start: TimeToLayoutDay(start),
+		End:   TimeToLayoutDay(end),
+	}
+}
+
+// String canonical form of relative period
+func (r *RelativePeriod) String() string {
+	switch r.kind {
+	case relativeToday:
+		return "today"
+	case relativeYesterday:
+		return "yesterday"
+	case relativeLast:
+		return fmt.Sprintf("last %d %s", r.count, pluralUnit(r.unit, r.count))
+	case relativeLastFull:
+		return fmt.Sprintf("last %d full %s", r.count, pluralUnit(r.unit, r.count))
+	case relativePrevious:
+		return fmt.Sprintf("previous %s", r.unit)
+	case relativeThis:
+		return fmt.Sprintf("this %s", r.unit)
+	case relativeToDate:
+		for k, v := range toDateAlias {
+			if v == r.unit {
+				return k
+			}
+		}
+	case relativeInterval:
+		if r.duration == nil {
+			return fmt.Sprintf("%s/%s", TimeToLayoutDay(r.start), TimeToLayoutDay(r.end))
+		}
+
+		if r.end.IsZero() {
+			return fmt.Sprintf("%s/%s", TimeToLayoutDay(r.start), r.duration.String())
+		}
+
+		return fmt.Sprintf("%s/%s", r.duration.String(), TimeToLayoutDay(r.end))
+	}
+
+	return ""
+}
+
+func parsePeriodUnit(str string, allowPlural bool) (PeriodUnit, bool) {
+	if allowPlural {
+		str = strings.TrimSuffix(str, "s")
+	}
+
+	switch PeriodUnit(str) {
+	case UnitDay, UnitWeek, UnitMonth, UnitQuarter, UnitYear:
+		return PeriodUnit(str), true
+	}
+
+	return "", false
+}
+
+func pluralUnit(unit PeriodUnit, count int) string {
+	if count == 1 {
+		return string(unit)
+	}
+
+	return string(unit) + "s"
+}
+
+// unitStart returns first day of unit which contains day
+func unitStart(day time.Time, unit PeriodUnit) time.Time {
+	switch unit {
+	case UnitWeek:
+		return WeekStart(day, time.Monday)
+	case UnitMonth:
+		return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case UnitQuarter:
+		month := day.Month() - (day.Month()-1)%3
+		return time.Date(day.Year(), month, 1, 0, 0, 0, 0, time.UTC)
+	case UnitYear:
+		return time.Date(day.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	return day
+}
+
+// addPeriodUnits shift day by n units, day of month is clamped
+func addPeriodUnits(day time.Time, unit PeriodUnit, n int) time.Time {
+	switch unit {
+	case UnitWeek:
+		return day.AddDate(0, 0, 7*n)
+	case UnitMonth:
+		return AddMonths(day, n, MonthClamp)
+	case UnitQuarter:
+		return AddMonths(day, 3*n, MonthClamp)
+	case UnitYear:
+		return AddMonths(day, 12*n, MonthClamp)
+	}
+
+	return day.AddDate(0, 0, n)
+}
+
+// ***************************************
+// ********* ISO 8601 intervals **********
+// ***************************************
+
+// isoDuration date part of ISO 8601 duration, like P1Y2M3W4D
+type isoDuration struct {
+	years  int
+	months int
+	weeks  int
+	days   int
+}
+
+func parseISODuration(str string) (*isoDuration, error) {
+	invalid := errors.New(fmt.Sprintf("invalid ISO 8601 duration: %q, should be format of PnYnMnWnD", str))
+
+	if len(str) < 3 || str[0] != 'P' {
+		return nil, invalid
+	}
+
+	res := &isoDuration{}
+	num := ""
+	// designators must follow the order Y, M, W, D and appear at most once
+	designators := "YMWD"
+	for _, c := range str[1:] {
+		if c >= '0' && c <= '9' {
+			num += string(c)
+			continue
+		}
+
+		if num == "" {
+			return nil, invalid
+		}
+
+		v, err := strconv.Atoi(num)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("invalid ISO 8601 duration: %q, %v", str, err))
+		}
+		num = ""
+
+		// time part like PT1H is not supported, TimePeriod is day granular
+		idx := strings.IndexRune(designators, c)
+		if idx < 0 {
+			return nil, invalid
+		}
+		designators = designators[idx+1:]
+
+		switch c {
+		case 'Y':
+			res.years = v
+		case 'M':
+			res.months = v
+		case 'W':
+			res.weeks = v
+		case 'D':
+			res.days = v
+		}
+	}
+
+	if num != "" || res.String() == "P0D" {
+		return nil, invalid
+	}
+
+	return res, nil
+}
+
+// addTo shift day by sign * duration, day of month is clamped
+func (d *isoDuration) addTo(day time.Time, sign int) time.Time {
+	day = AddMonths(day, sign*(d.years*12+d.months), MonthClamp)
+	return day.AddDate(0, 0, sign*(d.weeks*7+d.days))
+}
+
+func (d *isoDuration) String() string {
+	res := "P"
+
+	if d.years > 0 {
+		res += fmt.Sprintf("%dY", d.years)
+	}
+
+	if d.months > 0 {
+		res += fmt.Sprintf("%dM", d.months)
+	}
+
+	if d.weeks > 0 {
+		res += fmt.Sprintf("%dW", d.weeks)
+	}
+
+	if d.days > 0 {
+		res += fmt.Sprintf("%dD", d.days)
+	}
+
+	if res == "P" {
+		return "P0D"
+	}
+
+	return res
+}
+
+// parseISOInterval parse start/end, start/duration or duration/end
+func parseISOInterval(str string) (*RelativePeriod, error) {
+	parts := strings.Split(str, "/")
+	if len(parts) != 2 {
+		return nil, errors.New(fmt.Sprintf("invalid ISO 8601 interval: %q", str))
+	}
+
+	res := &RelativePeriod{kind: relativeInterval}
+
+	parseDay := func(in string) (time.Time, error) {
+		day, ok := ToStdDayLayout(in)
+		if !ok {
+			return time.Time{}, errors.New(fmt.Sprintf("invalid date in ISO 8601 interval: %q, should be format of YYYY-MM-DD", in))
+		}
+
+		return time.Parse("2006-01-02", day)
+	}
+
+	left, right := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+
+	var err error
+	switch {
+	case strings.HasPrefix(left, "P"):
+		if res.duration, err = parseISODuration(left); err != nil {
+			return nil, err
+		}
+		if res.end, err = parseDay(right); err != nil {
+			return nil, err
+		}
+	case strings.HasPrefix(right, "P"):
+		if res.start, err = parseDay(left); err != nil {
+			return nil, err
+		}
+		if res.duration, err = parseISODuration(right); err != nil {
+			return nil, err
+		}
+	default:
+		if res.start, err = parseDay(left); err != nil {
+			return nil, err
+		}
+		if res.end, err = parseDay(right); err != nil {
+			return nil, err
+		}
+		if res.start.After(res.end) {
+			return nil, errors.New(fmt.Sprintf("invalid ISO 8601 interval: %q, start is after end", str))
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,78 @@
+package ptime
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResolveRelativePeriod(t *testing.T) {
+	// Wednesday
+	ref := time.Date(2024, 5, 15, 10, 0, 0, 0, time.UTC)
+
+	cases := map[string]string{
+		"today":              "2024-05-15->2024-05-15",
+		"yesterday":          "2024-05-14->2024-05-14",
+		"last 7 days":        "2024-05-09->2024-05-15",
+		"last 3 months":      "2024-02-16->2024-05-15",
+		"last 3 full months": "2024-02-01->2024-04-30",
+		"last 2 full weeks":  "2024-04-29->2024-05-12",
+		"previous month":     "2024-04-01->2024-04-30",
+		"last quarter":       "2024-01-01->2024-03-31",
+		"this month":         "2024-05-01->2024-05-31",
+		"MTD":                "2024-05-01->2024-05-15",
+		"QTD":                "2024-04-01->2024-05-15",
+		"YTD":                "2024-01-01->2024-05-15",
+		"WTD":                "2024-05-13->2024-05-15",
+		"2024-01-01/P3M":     "2024-01-01->2024-03-31",
+		"P3M/2024-03-31":     "2024-01-01->2024-03-31",
+		"2024-01-01/P2W":     "2024-01-01->2024-01-14",
+		"2024-1-5/2024-2-1":  "2024-01-05->2024-02-01",
+	}
+
+	for expr, expected := range cases {
+		tp, err := ResolveRelativePeriod(expr, ref)
+		if err != nil {
+			t.Errorf("failed to parse %q: %v", expr, err)
+			continue
+		}
+
+		if tp.String() != expected {
+			t.Errorf("%q: got %q, wanted %q", expr, tp.String(), expected)
+		}
+	}
+}
+
+func TestRelativePeriodCanonical(t *testing.T) {
+	cases := map[string]string{
+		"Last 1 Days":       "last 1 day",
+		"past 2 full weeks": "last 2 full weeks",
+		"last full month":   "last 1 full month",
+		"last month":        "previous month",
+		"mtd":               "mtd",
+		"2024-1-1/P1Y0M":    "2024-01-01/P1Y",
+	}
+
+	for expr, expected := range cases {
+		rp, err := ParseRelativePeriod(expr)
+		if err != nil {
+			t.Errorf("failed to parse %q: %v", expr, err)
+			continue
+		}
+
+		if rp.String() != expected {
+			t.Errorf("%q: got %q, wanted %q", expr, rp.String(), expected)
+		}
+
+		again, err := ParseRelativePeriod(rp.String())
+		if err != nil || again.String() != rp.String() {
+			t.Errorf("%q: canonical form is not round-trippable", expr)
+		}
+	}
+
+	for _, expr := range []string{"", "last", "last 0 days", "next month", "2024-01-01/PT1H", "2024-02-01/2024-01-01",
+		"2024-01-01/P1D2D", "2024-01-01/P1D1M", "2024-01-01/PT1S1H", "2024-01-01/P99999999999999999999D"} {
+		if _, err := ParseRelativePeriod(expr); err == nil {
+			t.Errorf("%q: expected error", expr)
+		}
+	}
+}
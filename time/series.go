@@ -0,0 +1,665 @@
+/*
+ * Copyright (c) 2022 The Mof Authors
+ */
+
+package ptime
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ************************************
+// *********** Granularity ************
+// ************************************
+
+// Granularity size of bucket in Series
+type Granularity string
+
+const (
+	GranularityHourly    Granularity = Hourly
+	GranularityDaily     Granularity = Daily
+	GranularityWeekly    Granularity = "weekly"
+	GranularityMonthly   Granularity = Monthly
+	GranularityQuarterly Granularity = "quarterly"
+	GranularityYearly    Granularity = Yearly
+)
+
+// granularities supported granularities, from finest to coarsest
+var granularities = []Granularity{
+	GranularityHourly,
+	GranularityDaily,
+	GranularityWeekly,
+	GranularityMonthly,
+	GranularityQuarterly,
+	GranularityYearly,
+}
+
+// granularityRank used to check whether a granularity is coarser than another, built from granularities
+var granularityRank = func() map[Granularity]int {
+	res := make(map[Granularity]int, len(granularities))
+	for i := range granularities {
+		res[granularities[i]] = i
+	}
+	return res
+}()
+
+// Validate checks whether granularity is supported
+func (g Granularity) Validate() error {
+	if _, ok := granularityRank[g]; !ok {
+		return errors.New(fmt.Sprintf("invalid granularity: %s", g))
+	}
+
+	return nil
+}
+
+// Truncate returns start of bucket which contains ts, result is in UTC
+//
+// Hourly buckets are in UTC, since their keys end with Z. Other buckets follow wall clock of ts.
+func (g Granularity) Truncate(ts time.Time) time.Time {
+	year, month, day := ts.Date()
+
+	switch g {
+	case GranularityHourly:
+		return ts.UTC().Truncate(time.Hour)
+	case GranularityWeekly:
+		return WeekStart(ts, time.Monday)
+	case GranularityMonthly:
+		return time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	case GranularityQuarterly:
+		return time.Date(year, month-(month-1)%3, 1, 0, 0, 0, 0, time.UTC)
+	case GranularityYearly:
+		return time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// Next returns start of next bucket
+func (g Granularity) Next(ts time.Time) time.Time {
+	ts = g.Truncate(ts)
+
+	switch g {
+	case GranularityHourly:
+		return ts.Add(time.Hour)
+	case GranularityWeekly:
+		return ts.AddDate(0, 0, 7)
+	case GranularityMonthly:
+		return ts.AddDate(0, 1, 0)
+	case GranularityQuarterly:
+		return ts.AddDate(0, 3, 0)
+	case GranularityYearly:
+		return ts.AddDate(1, 0, 0)
+	}
+
+	return ts.AddDate(0, 0, 1)
+}
+
+// Format returns bucket key of ts
+//
+// hourly:    2006-01-02T15:00Z
+// daily:     2006-01-02
+// weekly:    2006-W01, ISO 8601 week
+// monthly:   2006-01
+// quarterly: 2006-Q1
+// yearly:    2006
+func (g Granularity) Format(ts time.Time) string {
+	ts = g.Truncate(ts)
+
+	switch g {
+	case GranularityHourly:
+		return ts.Format("2006-01-02T15:04Z")
+	case GranularityWeekly:
+		return ISOWeekOf(ts).String()
+	case GranularityMonthly:
+		return TimeToLayoutMonth(ts)
+	case GranularityQuarterly:
+		return fmt.Sprintf("%d-Q%d", ts.Year(), (int(ts.Month())-1)/3+1)
+	case GranularityYearly:
+		return fmt.Sprintf("%d", ts.Year())
+	}
+
+	return TimeToLayoutDay(ts)
+}
+
+// Parse parse bucket key, anything accepted by StringToTime is truncated to bucket as well
+func (g Granularity) Parse(str string) (time.Time, error) {
+	str = strings.TrimSpace(str)
+
+	// 2006-W01
+	if week, err := ParseISOWeek(str); err == nil {
+		return g.Truncate(week.Start()), nil
+	}
+
+	// 2006-Q1
+	if i := strings.Index(str, "-Q"); i > 0 {
+		year, err1 := strconv.Atoi(str[:i])
+		quarter, err2 := strconv.Atoi(str[i+2:])
+		if err1 != nil || err2 != nil || quarter < 1 || quarter > 4 {
+			return time.Time{}, errors.New(fmt.Sprintf("invalid quarter: %s, should be format of YYYY-QN", str))
+		}
+
+		return g.Truncate(time.Date(year, time.Month(quarter*3-2), 1, 0, 0, 0, 0, time.UTC)), nil
+	}
+
+	// 2006
+	if len(str) == 4 && isDigits(str) {
+		year, _ := strconv.Atoi(str)
+		return g.Truncate(time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)), nil
+	}
+
+	ts, err := StringToTime(str)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return g.Truncate(ts), nil
+}
+
+// ************************************
+// *********** Aggregation ************
+// ************************************
+
+// Aggregation how values in same bucket are combined
+type Aggregation string
+
+const (
+	AggregateSum Aggregation = "sum"
+	AggregateAvg Aggregation = "avg"
+	AggregateMax Aggregation = "max"
+	AggregateMin Aggregation = "min"
+)
+
+// apply aggregate values, NaN is skipped and NaN is returned if nothing left
+func (a Aggregation) apply(values []float64) (float64, error) {
+	res, count := 0.0, 0
+
+	for _, v := range values {
+		if math.IsNaN(v) {
+			continue
+		}
+
+		switch {
+		case count == 0:
+			res = v
+		case a == AggregateSum || a == AggregateAvg:
+			res += v
+		case a == AggregateMax:
+			res = math.Max(res, v)
+		case a == AggregateMin:
+			res = math.Min(res, v)
+		}
+		count++
+	}
+
+	switch a {
+	case AggregateSum, AggregateAvg, AggregateMax, AggregateMin:
+	default:
+		return 0, errors.New(fmt.Sprintf("invalid aggregation: %s", a))
+	}
+
+	if count == 0 {
+		return math.NaN(), nil
+	}
+
+	if a == AggregateAvg {
+		res = res / float64(count)
+	}
+
+	return res, nil
+}
+
+// ************************************
+// ************* Series ***************
+// ************************************
+
+// SeriesPoint a bucket and its value
+type SeriesPoint struct {
+	Bucket string
+	Value  float64
+}
+
+type seriesPoint struct {
+	ts    time.Time
+	value float64
+}
+
+// Series values keyed by buckets of granularity, buckets are always sorted
+//
+// Missing values are represented by NaN, which are skipped by aggregations.
+type Series struct {
+	granularity Granularity
+	points      []seriesPoint
+}
+
+// NewSeries create an empty Series
+func NewSeries(g Granularity) (*Series, error) {
+	if err := g.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &Series{granularity: g, points: make([]seriesPoint, 0)}, nil
+}
+
+// SeriesFromMap create Series from map of bucket -> value, like map produced with TimeToLayoutDay
+//
+// Values fall into the same bucket are summed.
+func SeriesFromMap(g Granularity, m map[string]float64) (*Series, error) {
+	res, err := NewSeries(g)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range m {
+		if err := res.Add(k, v); err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// Granularity of Series
+func (s *Series) Granularity() Granularity {
+	return s.granularity
+}
+
+// Len number of buckets
+func (s *Series) Len() int {
+	return len(s.points)
+}
+
+// Set set value of bucket
+func (s *Series) Set(bucket string, v float64) error {
+	ts, err := s.granularity.Parse(bucket)
+	if err != nil {
+		return err
+	}
+
+	s.SetTime(ts, v)
+	return nil
+}
+
+// Add add value to bucket
+func (s *Series) Add(bucket string, v float64) error {
+	ts, err := s.granularity.Parse(bucket)
+	if err != nil {
+		return err
+	}
+
+	s.AddTime(ts, v)
+	return nil
+}
+
+// SetTime set value of bucket which contains ts
+func (s *Series) SetTime(ts time.Time, v float64) {
+	i, found := s.search(ts)
+	if found {
+		s.points[i].value = v
+		return
+	}
+
+	s.insert(i, seriesPoint{ts: s.granularity.Truncate(ts), value: v})
+}
+
+// AddTime add value to bucket which contains ts, missing value is treated as 0
+func (s *Series) AddTime(ts time.Time, v float64) {
+	i, found := s.search(ts)
+	if found {
+		if math.IsNaN(s.points[i].value) {
+			s.points[i].value = 0
+		}
+
+		s.points[i].value += v
+		return
+	}
+
+	s.insert(i, seriesPoint{ts: s.granularity.Truncate(ts), value: v})
+}
+
+// Get get value of bucket
+func (s *Series) Get(bucket string) (float64, bool) {
+	ts, err := s.granularity.Parse(bucket)
+	if err != nil {
+		return 0, false
+	}
+
+	i, found := s.search(ts)
+	if !found {
+		return 0, false
+	}
+
+	return s.points[i].value, true
+}
+
+// Buckets returns sorted bucket keys
+func (s *Series) Buckets() []string {
+	res := make([]string, 0, len(s.points))
+
+	for i := range s.points {
+		res = append(res, s.granularity.Format(s.points[i].ts))
+	}
+
+	return res
+}
+
+// Values returns values in order of buckets
+func (s *Series) Values() []float64 {
+	res := make([]float64, 0, len(s.points))
+
+	for i := range s.points {
+		res = append(res, s.points[i].value)
+	}
+
+	return res
+}
+
+// Points returns buckets and values in order
+func (s *Series) Points() []SeriesPoint {
+	res := make([]SeriesPoint, 0, len(s.points))
+
+	for i := range s.points {
+		res = append(res, SeriesPoint{
+			Bucket: s.granularity.Format(s.points[i].ts),
+			Value:  s.points[i].value,
+		})
+	}
+
+	return res
+}
+
+// ToMap convert to map of bucket -> value
+func (s *Series) ToMap() map[string]float64 {
+	res := make(map[string]float64, len(s.points))
+
+	for i := range s.points {
+		res[s.granularity.Format(s.points[i].ts)] = s.points[i].value
+	}
+
+	return res
+}
+
+// Fill returns a copy of Series with every bucket of tp, missing buckets are set to value
+//
+// Use math.NaN() as value to mark buckets as missing. If tp is nil, gaps between
+// first bucket and last bucket are filled.
+func (s *Series) Fill(tp *TimePeriod, value float64) (*Series, error) {
+	res := s.copy()
+
+	var start, end time.Time
+	if tp != nil {
+		sp, err := tp.toSpan()
+		if err != nil {
+			return nil, err
+		}
+		start, end = sp.start, sp.end
+	} else if len(s.points) > 0 {
+		start, end = s.points[0].ts, s.points[len(s.points)-1].ts.Add(time.Nanosecond)
+	}
+
+	for ts := s.granularity.Truncate(start); ts.Before(end); ts = s.granularity.Next(ts) {
+		if _, found := res.search(ts); !found {
+			res.SetTime(ts, value)
+		}
+	}
+
+	return res, nil
+}
+
+// Resample convert Series to a coarser granularity, like daily -> monthly -> quarterly
+func (s *Series) Resample(g Granularity, agg Aggregation) (*Series, error) {
+	if err := g.Validate(); err != nil {
+		return nil, err
+	}
+
+	if granularityRank[g] < granularityRank[s.granularity] {
+		return nil, errors.New(fmt.Sprintf("failed to resample from %s to %s, granularity should be coarser",
+			s.granularity, g))
+	}
+
+	if s.granularity == GranularityWeekly && g != GranularityWeekly {
+		// a week may span two months or two years
+		return nil, errors.New(fmt.Sprintf("failed to resample from %s to %s, weeks do not nest into %s buckets",
+			s.granularity, g, g))
+	}
+
+	res, _ := NewSeries(g)
+
+	for i := 0; i < len(s.points); {
+		bucket := g.Truncate(s.points[i].ts)
+		values := make([]float64, 0)
+
+		for ; i < len(s.points) && g.Truncate(s.points[i].ts).Equal(bucket); i++ {
+			values = append(values, s.points[i].value)
+		}
+
+		v, err := agg.apply(values)
+		if err != nil {
+			return nil, err
+		}
+
+		res.points = append(res.points, seriesPoint{ts: bucket, value: v})
+	}
+
+	return res, nil
+}
+
+// Align returns copies of two Series with the same buckets, missing buckets are set to fill
+func (s *Series) Align(other *Series, fill float64) (*Series, *Series, error) {
+	if s.granularity != other.granularity {
+		return nil, nil, errors.New(fmt.Sprintf("failed to align series, granularity mismatch: %s and %s",
+			s.granularity, other.granularity))
+	}
+
+	a, b := s.copy(), other.copy()
+
+	for i := range other.points {
+		if _, found := a.search(other.points[i].ts); !found {
+			a.SetTime(other.points[i].ts, fill)
+		}
+	}
+
+	for i := range s.points {
+		if _, found := b.search(s.points[i].ts); !found {
+			b.SetTime(s.points[i].ts, fill)
+		}
+	}
+
+	return a, b, nil
+}
+
+// Cumulative returns running total of Series, NaN is skipped
+func (s *Series) Cumulative() *Series {
+	res := s.copy()
+
+	total := 0.0
+	for i := range res.points {
+		if !math.IsNaN(res.points[i].value) {
+			total += res.points[i].value
+		}
+		res.points[i].value = total
+	}
+
+	return res
+}
+
+// Rolling returns aggregation over trailing window of buckets,
+// buckets without a full window are set to NaN
+func (s *Series) Rolling(window int, agg Aggregation) (*Series, error) {
+	if window < 1 {
+		return nil, errors.New(fmt.Sprintf("invalid rolling window: %d", window))
+	}
+
+	res := s.copy()
+
+	for i := range s.points {
+		if i+1 < window {
+			res.points[i].value = math.NaN()
+			continue
+		}
+
+		values := make([]float64, 0, window)
+		for j := i + 1 - window; j <= i; j++ {
+			values = append(values, s.points[j].value)
+		}
+
+		v, err := agg.apply(values)
+		if err != nil {
+			return nil, err
+		}
+		res.points[i].value = v
+	}
+
+	return res, nil
+}
+
+type seriesPointJSON struct {
+	Bucket string   `json:"bucket"`
+	Value  *float64 `json:"value"`
+}
+
+type seriesJSON struct {
+	Granularity Granularity       `json:"granularity"`
+	Points      []seriesPointJSON `json:"points"`
+}
+
+// MarshalJSON encode Series as {"granularity": "daily", "points": [{"bucket": "2024-01-01", "value": 1}]},
+// NaN is encoded as null
+func (s *Series) MarshalJSON() ([]byte, error) {
+	res := seriesJSON{
+		Granularity: s.granularity,
+		Points:      make([]seriesPointJSON, 0, len(s.points)),
+	}
+
+	for i := range s.points {
+		p := seriesPointJSON{Bucket: s.granularity.Format(s.points[i].ts)}
+		if v := s.points[i].value; !math.IsNaN(v) {
+			p.Value = &v
+		}
+
+		res.Points = append(res.Points, p)
+	}
+
+	return json.Marshal(res)
+}
+
+// UnmarshalJSON decode Series encoded by MarshalJSON
+func (s *Series) UnmarshalJSON(bytes []byte) error {
+	raw := seriesJSON{}
+	if err := json.Unmarshal(bytes, &raw); err != nil {
+		return err
+	}
+
+	res, err := NewSeries(raw.Granularity)
+	if err != nil {
+		return err
+	}
+
+	for i := range raw.Points {
+		v := math.NaN()
+		if raw.Points[i].Value != nil {
+			v = *raw.Points[i].Value
+		}
+
+		if err := res.Set(raw.Points[i].Bucket, v); err != nil {
+			return err
+		}
+	}
+
+	*s = *res
+	return nil
+}
+
+// WriteCSV write Series as CSV with header bucket,value, NaN is written as empty cell
+func (s *Series) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write([]string{"bucket", "value"}); err != nil {
+		return err
+	}
+
+	for i := range s.points {
+		v := ""
+		if !math.IsNaN(s.points[i].value) {
+			v = strconv.FormatFloat(s.points[i].value, 'f', -1, 64)
+		}
+
+		if err := writer.Write([]string{s.granularity.Format(s.points[i].ts), v}); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// ReadSeriesCSV read Series written by WriteCSV
+func ReadSeriesCSV(r io.Reader, g Granularity) (*Series, error) {
+	res, err := NewSeries(g)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range records {
+		if len(records[i]) != 2 {
+			return nil, errors.New(fmt.Sprintf("invalid CSV line %d, should be bucket,value", i+1))
+		}
+
+		if i == 0 && records[i][0] == "bucket" {
+			continue
+		}
+
+		v := math.NaN()
+		if len(strings.TrimSpace(records[i][1])) > 0 {
+			if v, err = strconv.ParseFloat(strings.TrimSpace(records[i][1]), 64); err != nil {
+				return nil, errors.New(fmt.Sprintf("invalid value at CSV line %d: %s", i+1, records[i][1]))
+			}
+		}
+
+		if err := res.Set(records[i][0], v); err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// search returns index of bucket which contains ts, or index to insert
+func (s *Series) search(ts time.Time) (int, bool) {
+	ts = s.granularity.Truncate(ts)
+
+	i := sort.Search(len(s.points), func(i int) bool {
+		return !s.points[i].ts.Before(ts)
+	})
+
+	return i, i < len(s.points) && s.points[i].ts.Equal(ts)
+}
+
+func (s *Series) insert(i int, p seriesPoint) {
+	s.points = append(s.points, seriesPoint{})
+	copy(s.points[i+1:], s.points[i:])
+	s.points[i] = p
+}
+
+func (s *Series) copy() *Series {
+	res := &Series{
+		granularity: s.granularity,
+		points:      make([]seriesPoint, len(s.points)),
+	}
+
+	copy(res.points, s.points)
+	return res
+}
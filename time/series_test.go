@@ -0,0 +1,121 @@
+package ptime
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSeriesResample(t *testing.T) {
+	s, err := SeriesFromMap(GranularityDaily, map[string]float64{
+		"2024-01-30": 1,
+		"2024-1-31":  2,
+		"2024-02-01": 3,
+		"2024-04-01": 4,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	monthly, err := s.Resample(GranularityMonthly, AggregateSum)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]float64{"2024-01": 3, "2024-02": 3, "2024-04": 4}
+	for k, v := range expected {
+		if got, _ := monthly.Get(k); got != v {
+			t.Errorf("%s: got %v, wanted %v", k, got, v)
+		}
+	}
+
+	quarterly, _ := monthly.Resample(GranularityQuarterly, AggregateMax)
+	if quarterly.Buckets()[0] != "2024-Q1" || quarterly.Values()[0] != 3 {
+		t.Errorf("got %v %v, wanted 2024-Q1 = 3", quarterly.Buckets(), quarterly.Values())
+	}
+
+	if _, err := monthly.Resample(GranularityDaily, AggregateSum); err == nil {
+		t.Errorf("expected error when resampling to finer granularity")
+	}
+}
+
+func TestSeriesFillAndRolling(t *testing.T) {
+	s, _ := NewSeries(GranularityDaily)
+	_ = s.Set("2024-01-01", 1)
+	_ = s.Set("2024-01-04", 4)
+
+	filled, err := s.Fill(&TimePeriod{Start: "2024-01-01", End: "2024-01-05"}, math.NaN())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if filled.Len() != 5 {
+		t.Fatalf("got %d buckets, wanted %d", filled.Len(), 5)
+	}
+
+	cum := filled.Cumulative().Values()
+	if cum[4] != 5 {
+		t.Errorf("got %v, wanted cumulative total 5", cum)
+	}
+
+	rolling, _ := filled.Rolling(3, AggregateSum)
+	values := rolling.Values()
+	if !math.IsNaN(values[1]) || values[2] != 1 || values[3] != 4 {
+		t.Errorf("got %v, wanted [NaN NaN 1 4 4]", values)
+	}
+}
+
+func TestSeriesEncoding(t *testing.T) {
+	s, _ := NewSeries(GranularityMonthly)
+	_ = s.Set("2024-01", 1.5)
+	_ = s.Set("2024-02", math.NaN())
+
+	bytesJSON, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"granularity":"monthly","points":[{"bucket":"2024-01","value":1.5},{"bucket":"2024-02","value":null}]}`
+	if string(bytesJSON) != expected {
+		t.Errorf("got %s, wanted %s", bytesJSON, expected)
+	}
+
+	decoded := &Series{}
+	if err := json.Unmarshal(bytesJSON, decoded); err != nil || decoded.Len() != 2 {
+		t.Errorf("failed to decode series: %v", err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := s.WriteCSV(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if buf.String() != "bucket,value\n2024-01,1.5\n2024-02,\n" {
+		t.Errorf("got %q", buf.String())
+	}
+
+	fromCSV, err := ReadSeriesCSV(buf, GranularityMonthly)
+	if err != nil || fromCSV.Len() != 2 {
+		t.Errorf("failed to read csv: %v", err)
+	}
+}
+
+func TestGranularityTruncateLocation(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 30, 0, 0, time.FixedZone("UTC+8", 8*3600))
+
+	// hourly buckets are in UTC, so that key 2024-01-01T19:00Z is the real instant
+	if got := GranularityHourly.Truncate(ts); !got.Equal(time.Date(2024, 1, 1, 19, 0, 0, 0, time.UTC)) {
+		t.Errorf("got %s, wanted 2024-01-01T19:00Z", got)
+	}
+
+	if got := GranularityHourly.Format(ts); got != "2024-01-01T19:00Z" {
+		t.Errorf("got %q, wanted %q", got, "2024-01-01T19:00Z")
+	}
+
+	// other buckets follow wall clock of ts
+	if got := GranularityDaily.Format(ts); got != "2024-01-02" {
+		t.Errorf("got %q, wanted %q", got, "2024-01-02")
+	}
+}
@@ -0,0 +1,128 @@
+/*
+ * Copyright (c) 2022 The Mof Authors
+ */
+
+package ptime
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Value implements driver.Valuer
+//
+// TimePeriod is stored as canonical Postgres daterange text like [2024-01-01,2024-02-01),
+// which could be stored in a daterange column or a text column of any database.
+// An empty TimePeriod is stored as NULL.
+func (t TimePeriod) Value() (driver.Value, error) {
+	if t.Start == "" && t.End == "" {
+		return nil, nil
+	}
+
+	sp, err := t.toSpan()
+	if err != nil {
+		return nil, err
+	}
+
+	return fmt.Sprintf("[%s,%s)", TimeToLayoutDay(sp.start), TimeToLayoutDay(sp.end)), nil
+}
+
+// Scan implements sql.Scanner
+//
+// Accepts NULL, Postgres daterange text like [2024-01-01,2024-02-01) or (2023-12-31,2024-01-31],
+// JSON like {"start":"2024-01-01","end":"2024-01-31"} and compact form like 2024-01-01->2024-01-31.
+func (t *TimePeriod) Scan(src interface{}) error {
+	var str string
+
+	switch v := src.(type) {
+	case nil:
+		*t = TimePeriod{}
+		return nil
+	case []byte:
+		str = string(v)
+	case string:
+		str = v
+	default:
+		return errors.New(fmt.Sprintf("failed to scan %T into TimePeriod", src))
+	}
+
+	str = strings.TrimSpace(str)
+
+	switch {
+	case str == "" || str == "empty":
+		*t = TimePeriod{}
+		return nil
+	case strings.HasPrefix(str, "{") || strings.HasPrefix(str, "\""):
+		return t.UnmarshalJSON([]byte(str))
+	case strings.HasPrefix(str, "[") || strings.HasPrefix(str, "("):
+		res, err := parseDateRange(str)
+		if err != nil {
+			return err
+		}
+
+		*t = *res
+		return nil
+	}
+
+	return t.UnmarshalText([]byte(str))
+}
+
+// parseDateRange parse Postgres daterange text, unbounded range is not supported
+func parseDateRange(str string) (*TimePeriod, error) {
+	invalid := errors.New(fmt.Sprintf("invalid daterange: %s", str))
+
+	if len(str) < 2 {
+		return nil, invalid
+	}
+
+	lower, upper := str[0], str[len(str)-1]
+	if upper != ']' && upper != ')' {
+		return nil, invalid
+	}
+
+	parts := strings.Split(str[1:len(str)-1], ",")
+	if len(parts) != 2 {
+		return nil, invalid
+	}
+
+	bounds := make([]string, 0, 2)
+	for i := range parts {
+		bound := strings.Trim(strings.TrimSpace(parts[i]), "\"")
+		if len(bound) < 1 {
+			return nil, errors.New(fmt.Sprintf("unbounded daterange is not supported: %s", str))
+		}
+
+		bounds = append(bounds, bound)
+	}
+
+	start, err := StringToTime(bounds[0])
+	if err != nil || !IsStdDayLayout(bounds[0]) {
+		return nil, invalidFieldError("start", bounds[0])
+	}
+
+	end, err := StringToTime(bounds[1])
+	if err != nil || !IsStdDayLayout(bounds[1]) {
+		return nil, invalidFieldError("end", bounds[1])
+	}
+
+	if lower == '(' {
+		start = start.AddDate(0, 0, 1)
+	}
+
+	if upper == ')' {
+		end = end.AddDate(0, 0, -1)
+	}
+
+	res := &TimePeriod{
+		Start: TimeToLayoutDay(start),
+		End:   TimeToLayoutDay(end),
+	}
+
+	if err := res.Normalize(); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
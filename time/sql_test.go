@@ -0,0 +1,43 @@
+package ptime
+
+import (
+	"testing"
+)
+
+func TestTimePeriodValueAndScan(t *testing.T) {
+	v, err := (TimePeriod{Start: "2024-01", End: "2024-01-31"}).Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v != "[2024-01-01,2024-02-01)" {
+		t.Errorf("got %v, wanted %q", v, "[2024-01-01,2024-02-01)")
+	}
+
+	cases := []struct {
+		src      interface{}
+		expected string
+	}{
+		{src: "[2024-01-01,2024-02-01)", expected: "2024-01-01->2024-01-31"},
+		{src: []byte("(2023-12-31,2024-01-31]"), expected: "2024-01-01->2024-01-31"},
+		{src: `{"start":"2024-1-1","end":"2024-1-31"}`, expected: "2024-01-01->2024-01-31"},
+		{src: "2024-01-01->2024-01-31", expected: "2024-01-01->2024-01-31"},
+	}
+
+	for _, c := range cases {
+		tp := &TimePeriod{}
+		if err := tp.Scan(c.src); err != nil {
+			t.Errorf("failed to scan %v: %v", c.src, err)
+			continue
+		}
+
+		if tp.String() != c.expected {
+			t.Errorf("%v: got %q, wanted %q", c.src, tp.String(), c.expected)
+		}
+	}
+
+	tp := &TimePeriod{}
+	if err := tp.Scan("[,2024-01-01)"); err == nil {
+		t.Errorf("expected error for unbounded range")
+	}
+}
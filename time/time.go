@@ -6,14 +6,15 @@ package ptime
 
 import (
 	"fmt"
-	"github.com/pkg/errors"
 	"strings"
 	"time"
 )
 
 const (
+	Yearly  = "yearly"
 	Monthly = "monthly"
 	Daily   = "daily"
+	Hourly  = "hourly"
 )
 
 // ************************************
@@ -65,20 +66,22 @@ func GetLastXMonthTimePeriod(now time.Time, x int) *TimePeriod {
 // Start: should be YYYY-MM-DD
 // End: should be YYYY-MM-DD
 type TimePeriod struct {
-	Start string `json:"start"`
-	End   string `json:"end"`
+	Start string `yaml:"start" json:"start"`
+	End   string `yaml:"end" json:"end"`
 }
 
 // Validate valid time period should be format of YYYY-MM-DD
 func (t *TimePeriod) Validate() error {
 	// 1: invalid time format of start time
 	if _, err := StringToLayoutDaily(t.Start); err != nil {
-		return errors.New(fmt.Sprintf("invalid startTime: %s, should be format YYYY-MM-DD", t.Start))
+		return newValidationError("start", t.Start, ReasonInvalidFormat,
+			fmt.Sprintf("invalid startTime: %s, should be format YYYY-MM-DD", t.Start))
 	}
 
 	// 2: invalid format of end time
 	if _, err := StringToLayoutDaily(t.End); err != nil {
-		return errors.New(fmt.Sprintf("invalid endTime: %s, should be format of YYYY-MM-DD", t.End))
+		return newValidationError("end", t.End, ReasonInvalidFormat,
+			fmt.Sprintf("invalid endTime: %s, should be format of YYYY-MM-DD", t.End))
 	}
 
 	startTime, _ := StringToTime(t.Start)
@@ -86,14 +89,14 @@ func (t *TimePeriod) Validate() error {
 
 	// 3: start time is after today
 	if startTime.After(time.Now()) {
-		return errors.New("start time is out of range, should be before today")
+		return newValidationError("start", t.Start, ReasonStartInFuture,
+			"start time is out of range, should be before today")
 	}
 
 	// 4: start time is after end time
 	if startTime.Equal(endTime) || startTime.After(endTime) {
-		return errors.New(fmt.Sprintf("invalid startTime: %s endTime: %s, startTime is not before endTime",
-			t.Start, t.End))
-
+		return newValidationError("start", t.Start, ReasonStartAfterEnd,
+			fmt.Sprintf("invalid startTime: %s endTime: %s, startTime is not before endTime", t.Start, t.End))
 	}
 
 	return nil
@@ -218,13 +221,20 @@ func (t *TimePeriod) StartAndEndInMonth(month string) (time.Time, time.Time, err
 	// get currMonth as time.Time
 	currMonth, err := StringToTime(month)
 	if err != nil {
-		return resStart, resEnd, err
+		return resStart, resEnd, newValidationError("month", month, ReasonInvalidFormat, err.Error())
 	}
 
 	firstDay := FirstDayOfMonthTime(currMonth)
 	lastDay := LastDayOfMonthTime(currMonth)
-	startDay := t.ToStartTime()
-	endDay := t.ToEndTime()
+	startDay, err := StringToTime(t.Start)
+	if err != nil {
+		return resStart, resEnd, newValidationError("start", t.Start, ReasonInvalidFormat, err.Error())
+	}
+
+	endDay, err := StringToTime(t.End)
+	if err != nil {
+		return resStart, resEnd, newValidationError("end", t.End, ReasonInvalidFormat, err.Error())
+	}
 
 	firstDayNano := firstDay.UnixNano()
 	lastDayNano := lastDay.UnixNano()
@@ -235,12 +245,12 @@ func (t *TimePeriod) StartAndEndInMonth(month string) (time.Time, time.Time, err
 		firstDay.String(), lastDay.String(), startDay.String(), endDay.String())
 
 	if startDayNano > endDayNano {
-		return resStart, resEnd, errors.New(errMsg)
+		return resStart, resEnd, newValidationError("start", t.Start, ReasonStartAfterEnd, errMsg)
 	}
 
 	if endDayNano <= firstDayNano {
 		// case 1: [startDay, endDay, firstDay, lastDay] => error
-		return resStart, resEnd, errors.New(errMsg)
+		return resStart, resEnd, newValidationError("month", month, ReasonOutOfRange, errMsg)
 	} else if startDayNano <= firstDayNano && firstDayNano <= endDayNano && endDayNano <= lastDayNano {
 		// case 2: [startDay, firstDay, endDay, lastDay] => [firstDay, endDay]
 		return firstDay, endDay, nil
@@ -255,10 +265,10 @@ func (t *TimePeriod) StartAndEndInMonth(month string) (time.Time, time.Time, err
 		return startDay, lastDay, nil
 	} else if lastDayNano <= startDayNano {
 		// case 6: [firstDay, lastDay, startDay, endDay] => error
-		return resStart, resEnd, errors.New(errMsg)
+		return resStart, resEnd, newValidationError("month", month, ReasonOutOfRange, errMsg)
 	}
 
-	return resStart, resEnd, errors.New(errMsg)
+	return resStart, resEnd, newValidationError("month", month, ReasonOutOfRange, errMsg)
 }
 
 func (t *TimePeriod) OneMonthBefore() error {
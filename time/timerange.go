@@ -0,0 +1,152 @@
+/*
+ * Copyright (c) 2022 The Mof Authors
+ */
+
+package ptime
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimeRange half-open range of timestamps [Start, End)
+//
+// Unlike TimePeriod which is day granular with inclusive End, TimeRange carries full
+// timestamps, which is required by hourly data like AWS hourly cost and usage report.
+type TimeRange struct {
+	Start time.Time `yaml:"start" json:"start"`
+	End   time.Time `yaml:"end" json:"end"`
+}
+
+// NewTimeRange create TimeRange, start must be before end
+func NewTimeRange(start, end time.Time) (*TimeRange, error) {
+	res := &TimeRange{Start: start, End: end}
+
+	if err := res.Validate(); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// Validate valid time range should have Start before End
+func (r *TimeRange) Validate() error {
+	if !r.Start.Before(r.End) {
+		return newValidationError("start", r.Start.Format(time.RFC3339), ReasonStartAfterEnd,
+			fmt.Sprintf("invalid startTime: %s endTime: %s, startTime is not before endTime",
+				r.Start.Format(time.RFC3339), r.End.Format(time.RFC3339)))
+	}
+
+	return nil
+}
+
+// ToTimeRange convert TimePeriod to TimeRange in loc, from midnight of Start to midnight after End
+func (t *TimePeriod) ToTimeRange(loc *time.Location) (*TimeRange, error) {
+	sp, err := t.toSpan()
+	if err != nil {
+		return nil, err
+	}
+
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	return &TimeRange{
+		Start: time.Date(sp.start.Year(), sp.start.Month(), sp.start.Day(), 0, 0, 0, 0, loc),
+		End:   time.Date(sp.end.Year(), sp.end.Month(), sp.end.Day(), 0, 0, 0, 0, loc),
+	}, nil
+}
+
+// ToHourList returns hours of TimePeriod in UTC, like 2024-01-01T00:00Z
+func (t *TimePeriod) ToHourList() []string {
+	r, err := t.ToTimeRange(time.UTC)
+	if err != nil {
+		return make([]string, 0)
+	}
+
+	return r.ToHourList()
+}
+
+// ToTimePeriod returns days touched by TimeRange, in location of Start
+func (r *TimeRange) ToTimePeriod() *TimePeriod {
+	end := r.End.In(r.Start.Location()).Add(-time.Nanosecond)
+	if end.Before(r.Start) {
+		end = r.Start
+	}
+
+	return &TimePeriod{
+		Start: TimeToLayoutDay(r.Start),
+		End:   TimeToLayoutDay(end),
+	}
+}
+
+// Duration length of TimeRange
+func (r *TimeRange) Duration() time.Duration {
+	return r.End.Sub(r.Start)
+}
+
+// Contains checks whether ts is in [Start, End)
+func (r *TimeRange) Contains(ts time.Time) bool {
+	return !ts.Before(r.Start) && ts.Before(r.End)
+}
+
+// Overlaps checks whether two time ranges share any instant
+func (r *TimeRange) Overlaps(other *TimeRange) bool {
+	return r.Start.Before(other.End) && other.Start.Before(r.End)
+}
+
+// HourBuckets split TimeRange at boundaries of hour, first and last bucket may be partial
+//
+// Boundaries follow wall clock in location of Start.
+func (r *TimeRange) HourBuckets() []*TimeRange {
+	return r.buckets(func(ts time.Time) time.Time {
+		next := time.Date(ts.Year(), ts.Month(), ts.Day(), ts.Hour(), 0, 0, 0, ts.Location()).Add(time.Hour)
+		if !next.After(ts) {
+			// wall clock jumps back, like end of daylight saving time
+			next = ts.Add(time.Hour)
+		}
+		return next
+	})
+}
+
+// DayBuckets split TimeRange at boundaries of day, first and last bucket may be partial
+//
+// Boundaries follow wall clock in location of Start.
+func (r *TimeRange) DayBuckets() []*TimeRange {
+	return r.buckets(func(ts time.Time) time.Time {
+		return time.Date(ts.Year(), ts.Month(), ts.Day()+1, 0, 0, 0, 0, ts.Location())
+	})
+}
+
+// ToHourList returns hours touched by TimeRange in UTC, like 2024-01-01T00:00Z
+func (r *TimeRange) ToHourList() []string {
+	res := make([]string, 0)
+
+	for ts := r.Start.UTC().Truncate(time.Hour); ts.Before(r.End); ts = ts.Add(time.Hour) {
+		res = append(res, GranularityHourly.Format(ts))
+	}
+
+	return res
+}
+
+func (r *TimeRange) String() string {
+	return fmt.Sprintf("%s->%s", r.Start.Format(time.RFC3339), r.End.Format(time.RFC3339))
+}
+
+// buckets split TimeRange, next returns start of next bucket
+func (r *TimeRange) buckets(next func(time.Time) time.Time) []*TimeRange {
+	res := make([]*TimeRange, 0)
+
+	end := r.End.In(r.Start.Location())
+	for curr := r.Start; curr.Before(end); {
+		n := next(curr)
+		if n.After(end) {
+			n = end
+		}
+
+		res = append(res, &TimeRange{Start: curr, End: n})
+		curr = n
+	}
+
+	return res
+}
@@ -0,0 +1,63 @@
+package ptime
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeRangeBuckets(t *testing.T) {
+	start := time.Date(2024, 1, 1, 22, 30, 0, 0, time.UTC)
+	r, err := NewTimeRange(start, start.Add(3*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hours := r.HourBuckets()
+	if len(hours) != 4 {
+		t.Fatalf("got %d buckets, wanted %d", len(hours), 4)
+	}
+
+	if hours[0].Duration() != 30*time.Minute || hours[1].Start.Hour() != 23 {
+		t.Errorf("got %v, first bucket should be partial", hours)
+	}
+
+	days := r.DayBuckets()
+	if len(days) != 2 || days[1].Start != time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC) {
+		t.Errorf("got %v, wanted split at midnight", days)
+	}
+
+	if r.ToTimePeriod().String() != "2024-01-01->2024-01-02" {
+		t.Errorf("got %q, wanted %q", r.ToTimePeriod().String(), "2024-01-01->2024-01-02")
+	}
+
+	list := r.ToHourList()
+	if len(list) != 4 || list[0] != "2024-01-01T22:00Z" || list[3] != "2024-01-02T01:00Z" {
+		t.Errorf("got %v", list)
+	}
+}
+
+func TestTimePeriodToTimeRange(t *testing.T) {
+	loc := time.FixedZone("CST", 8*3600)
+	tp := &TimePeriod{Start: "2024-01-01", End: "2024-01-01"}
+
+	r, err := tp.ToTimeRange(loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Duration() != 24*time.Hour || len(r.HourBuckets()) != 24 {
+		t.Errorf("got %s, wanted one whole day", r)
+	}
+
+	if r.ToTimePeriod().String() != tp.String() {
+		t.Errorf("got %q, wanted %q", r.ToTimePeriod().String(), tp.String())
+	}
+
+	if len(tp.ToHourList()) != 24 {
+		t.Errorf("got %d hours, wanted %d", len(tp.ToHourList()), 24)
+	}
+
+	if _, err := NewTimeRange(r.End, r.Start); err == nil {
+		t.Errorf("expected error when start is after end")
+	}
+}
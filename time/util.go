@@ -7,41 +7,18 @@ package ptime
 import (
 	"errors"
 	"fmt"
-	"strconv"
 	"strings"
 	"time"
 )
 
-var layoutList = []string{
-	"2006-01-02",
-	"2006-1-2",
-	"2006-01",
-	"2006-1",
-	"2006-01-02T15:04Z",
-	ProphetFormat,
-	AwsCloudWatchFormat,
-	LinodeFormat,
-	time.ANSIC,
-	time.UnixDate,
-	time.RubyDate,
-	time.RFC822,
-	time.RFC822Z,
-	time.RFC850,
-	time.RFC1123,
-	time.RFC1123Z,
-	time.RFC3339,
-	time.RFC3339Nano,
-	time.Kitchen,
-	time.Stamp,
-	time.StampMilli,
-	time.StampMicro,
-	time.StampNano,
-}
-
 const (
 	ProphetFormat       = "2006-01-02 15:04:05"
 	AwsCloudWatchFormat = "2006-01-02 15:04:05 -0700 MST"
 	LinodeFormat        = "2006-01-02T15:04:05"
+
+	// AwsCloudWatchNumericZoneFormat same as AwsCloudWatchFormat but for
+	// time zones without abbreviation, like "2022-01-02 03:04:05 +0800 +0800"
+	AwsCloudWatchNumericZoneFormat = "2006-01-02 15:04:05 -0700 -0700"
 )
 
 // TimeToLayoutMonth converts time to YYYY-MM layout
@@ -203,113 +180,45 @@ func LastDayOfMonthTimeActual(ts time.Time) time.Time {
 	return newTs
 }
 
-// StringToTime try our best to parse string to time
+// StringToTime try our best to parse string to time, input is not trimmed
+//
+// Use Parser if matched layout, cleaning of input or strict parsing is required
 func StringToTime(str string) (time.Time, error) {
-	var err error
-	var ts time.Time
-
-	for _, l := range layoutList {
-		ts, err = time.Parse(l, str)
-		if err == nil {
-			break
-		}
-	}
-
+	ts, _, err := defaultParser.Parse(str)
 	return ts, err
 }
 
-func ToProphetFormatFromString(str string) (string, error) {
-	var err error
-	var ts time.Time
-
-	for _, l := range layoutList {
-		ts, err = time.Parse(l, str)
-		if err == nil {
-			break
-		}
+// epochOrStringToTime parse epoch timestamp in any unit, or fallback to StringToTime
+func epochOrStringToTime(str string) (time.Time, error) {
+	if IsEpoch(str) {
+		return ParseEpoch(str, EpochAuto)
 	}
 
-	if err == nil {
-		return ts.Format(ProphetFormat), nil
-	}
-
-	// parse as epoch time
-	if raw, err := strconv.ParseInt(str, 10, 64); err != nil {
-		return "", err
-	} else {
-		ts = time.UnixMilli(raw)
-	}
+	return StringToTime(str)
+}
 
-	return ts.Format(ProphetFormat), nil
+// ToProphetFormatFromString same as Convert(ProviderProphet, str)
+func ToProphetFormatFromString(str string) (string, error) {
+	return Convert(ProviderProphet, str)
 }
 
 func ToStdFormatFromString(str string) (string, error) {
-	var ts time.Time
-
-	// is the incoming ts is epoch?
-	if num, err := strconv.ParseInt(str, 10, 64); err == nil {
-		ts = time.Unix(num, 0)
-		return ts.Format(time.RFC3339Nano), nil
-	} else {
-		for _, l := range layoutList {
-			ts, err = time.Parse(l, str)
-			if err == nil {
-				break
-			}
-		}
-
-		if err == nil {
-			return ts.Format(time.RFC3339Nano), nil
-		}
+	ts, err := epochOrStringToTime(str)
+	if err != nil {
+		return ts.Format(time.RFC3339Nano), errors.New("invalid timestamp format")
 	}
 
-	return ts.Format(time.RFC3339Nano), errors.New("invalid timestamp format")
+	return ts.Format(time.RFC3339Nano), nil
 }
 
+// ToAlibabaFormatFromString same as Convert(ProviderAlibaba, str)
 func ToAlibabaFormatFromString(str string) (string, error) {
-	var ts time.Time
-
-	// is the incoming ts is epoch?
-	if num, err := strconv.ParseInt(str, 10, 64); err == nil {
-		ts = time.UnixMilli(num)
-		return ts.Format(time.RFC3339Nano), nil
-	} else {
-		for _, l := range layoutList {
-			ts, err = time.Parse(l, str)
-			if err == nil {
-				break
-			}
-		}
-
-		if err == nil {
-			return ts.Format(time.RFC3339Nano), nil
-		}
-	}
-
-	return ts.Format(time.RFC3339Nano), errors.New("invalid timestamp format")
+	return Convert(ProviderAlibaba, str)
 }
 
+// ToUCloudFormatFromString same as Convert(ProviderUCloud, str)
 func ToUCloudFormatFromString(str string) (string, error) {
-	var ts time.Time
-
-	// is the incoming ts is epoch?
-	if num, err := strconv.ParseInt(str, 10, 64); err == nil {
-		ts = time.Unix(num, 0)
-		return ts.Format(time.RFC3339Nano), nil
-	} else {
-		for _, l := range layoutList {
-			ts, err = time.Parse(l, str)
-			if err == nil {
-				break
-			}
-		}
-
-		if err == nil {
-			return ts.Format(time.RFC3339Nano), nil
-		}
-	}
-
-	return ts.Format(time.RFC3339Nano), errors.New("invalid timestamp format")
+	return Convert(ProviderUCloud, str)
 }
 
 // NextMonthLayoutMonthTime get next month
@@ -454,7 +363,7 @@ func CalcStartDayAndEndDay(timestamp, start, end string) (time.Time, time.Time,
 	// get currMonth as time.Time
 	currMonth, err := StringToTime(timestamp)
 	if err != nil {
-		return resStart, resEnd, err
+		return resStart, resEnd, newValidationError("timestamp", timestamp, ReasonInvalidFormat, err.Error())
 	}
 
 	// get firstDay of currMonth
@@ -464,12 +373,12 @@ func CalcStartDayAndEndDay(timestamp, start, end string) (time.Time, time.Time,
 	// get startDay as time.Time
 	startDay, err := StringToTime(start)
 	if err != nil {
-		return resStart, resEnd, err
+		return resStart, resEnd, newValidationError("start", start, ReasonInvalidFormat, err.Error())
 	}
 	// get endDay as time.Time
 	endDay, err := StringToTime(end)
 	if err != nil {
-		return resStart, resEnd, err
+		return resStart, resEnd, newValidationError("end", end, ReasonInvalidFormat, err.Error())
 	}
 
 	firstDayNano := firstDay.UnixNano()
@@ -481,12 +390,12 @@ func CalcStartDayAndEndDay(timestamp, start, end string) (time.Time, time.Time,
 		firstDay.String(), lastDay.String(), startDay.String(), endDay.String())
 
 	if startDayNano > endDayNano {
-		return resStart, resEnd, errors.New(errMsg)
+		return resStart, resEnd, newValidationError("start", start, ReasonStartAfterEnd, errMsg)
 	}
 
 	if endDayNano <= firstDayNano {
 		// case 1: [startDay, endDay, firstDay, lastDay] => error
-		return resStart, resEnd, errors.New(errMsg)
+		return resStart, resEnd, newValidationError("timestamp", timestamp, ReasonOutOfRange, errMsg)
 	} else if startDayNano <= firstDayNano && firstDayNano <= endDayNano && endDayNano <= lastDayNano {
 		// case 2: [startDay, firstDay, endDay, lastDay] => [firstDay, endDay]
 		return firstDay, endDay, nil
@@ -501,10 +410,10 @@ func CalcStartDayAndEndDay(timestamp, start, end string) (time.Time, time.Time,
 		return startDay, lastDay, nil
 	} else if lastDayNano <= startDayNano {
 		// case 6: [firstDay, lastDay, startDay, endDay] => error
-		return resStart, resEnd, errors.New(errMsg)
+		return resStart, resEnd, newValidationError("timestamp", timestamp, ReasonOutOfRange, errMsg)
 	}
 
-	return resStart, resEnd, errors.New(errMsg)
+	return resStart, resEnd, newValidationError("timestamp", timestamp, ReasonOutOfRange, errMsg)
 }
 
 // DaysInMonth
@@ -565,6 +474,17 @@ func OneMonthBeforeCertainDayTime(in time.Time, day int) time.Time {
 	return in
 }
 
+// ListDateForNaturalMonthSet
+// Collect dates of previous monthCount months which are "the same day" as date.
+//
+// A date X is collected if AddMonths(X, n, MonthClamp) lands on date, where n is how many months X is before date.
+// Days of each previous month are split among days of current month without overlap:
+//
+// 2023-03-15 => [2023-02-15, 2023-01-15, ...]
+// 2023-03-28 => [2023-02-28, 2023-01-28, ...]
+// 2023-03-31 => [2023-01-31, ...], nothing in February
+// 2023-02-28 => [2023-01-28, 2023-01-29, 2023-01-30, 2023-01-31, ...]
+// 2024-02-29 => [2024-01-29, 2024-01-30, 2024-01-31, ...]
 func ListDateForNaturalMonthSet(date string, monthCount int) []string {
 	res := make([]string, 0)
 
@@ -573,151 +493,11 @@ func ListDateForNaturalMonthSet(date string, monthCount int) []string {
 		return res
 	}
 
-	day := ts.Day()
-	month := ts.Month()
-	isLastDay := false
-	if ts.Add(24*time.Hour).Month() != month {
-		isLastDay = true
-	}
+	for i := 1; i <= monthCount; i++ {
+		days := NaturalMonthSources(ts, i, MonthClamp)
 
-	if day < 28 {
-		// common day, iterate and return date list
-		for i := 0; i < monthCount; i++ {
-			ts = OneMonthBeforeCertainDayTime(ts, day)
-			res = append(res, TimeToLayoutDay(ts))
-		}
-	} else {
-		// check the months
-		for i := 0; i < monthCount; i++ {
-			ts = OneMonthBeforeTime(ts)
-			newMonth := ts.Month()
-			newMonthStr := TimeToLayoutMonth(ts)
-			lastDayOfNewTs := LastDayOfMonthTime(ts)
-
-			switch day {
-			case 30:
-				// if last day:
-				// 		1,3,5,7,8,10,12: collect 30,31
-				// 		4,6,9,11: collect 30
-				// 		2: do nothing
-				// if not
-				// 		1,3,5,7,8,10,12: collect 30
-				// 		4,6,9,11: collect 30
-				//		2: do nothing
-
-				if isLastDay {
-					switch newMonth {
-					case 1, 3, 5, 7, 8, 10, 12:
-						res = append(res,
-							fmt.Sprintf("%s-30", newMonthStr),
-							fmt.Sprintf("%s-31", newMonthStr))
-					case 4, 6, 9, 11:
-						res = append(res,
-							fmt.Sprintf("%s-30", newMonthStr))
-					}
-				} else {
-					switch newMonth {
-					case 1, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12:
-						res = append(res,
-							fmt.Sprintf("%s-30", newMonthStr))
-					}
-				}
-			case 31:
-				// 1,3,5,7,8,10,12: collect 31
-				// 4,6,9,11: do nothing
-				// 2: do nothing
-				switch newMonth {
-				case 1, 3, 5, 7, 8, 10, 12:
-					res = append(res,
-						fmt.Sprintf("%s-31", newMonthStr))
-				}
-			case 29:
-				// if month == 2(which means last day):
-				// 		1,3,5,7,8,10,12: collect 29,30,31
-				// 		4,6,9,11: collect 29,30
-				// 		2: if newMonth last day == 29
-				//				collect: 29
-				//		   else:
-				//		        collect: 28
-				// else
-				//      1,3,4,5,6,7,8,9,10,11,12: collect 29
-				//		2: if newMonth last day == 29
-				//				collect: 29
-				//		   else:
-				//		        do nothing
-				if month == 2 {
-					switch newMonth {
-					case 1, 3, 5, 7, 8, 10, 12:
-						res = append(res,
-							fmt.Sprintf("%s-29", newMonthStr),
-							fmt.Sprintf("%s-30", newMonthStr),
-							fmt.Sprintf("%s-31", newMonthStr))
-					case 4, 6, 9, 11:
-						res = append(res,
-							fmt.Sprintf("%s-29", newMonthStr),
-							fmt.Sprintf("%s-30", newMonthStr))
-					case 2:
-						if lastDayOfNewTs.Day() == 29 {
-							res = append(res,
-								fmt.Sprintf("%s-29", newMonthStr))
-						} else {
-							res = append(res,
-								fmt.Sprintf("%s-28", newMonthStr))
-						}
-					}
-				} else {
-					switch newMonth {
-					case 1, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12:
-						res = append(res,
-							fmt.Sprintf("%s-29", newMonthStr))
-					case 2:
-						if lastDayOfNewTs.Day() == 29 {
-							res = append(res,
-								fmt.Sprintf("%s-29", newMonthStr))
-						}
-					}
-				}
-			case 28:
-				// if month == 2
-				//		if last day
-				//			1,3,5,7,8,10,12: collect 28,29,30,31
-				//       	4,6,9,11: collect 28,29,30
-				// 			2: collect 28 (29 if possible)
-				//      else
-				//			collect 28
-				// else
-				//		collect 28
-				if month == 2 {
-					if isLastDay {
-						switch newMonth {
-						case 1, 3, 5, 7, 8, 10, 12:
-							res = append(res,
-								fmt.Sprintf("%s-28", newMonthStr),
-								fmt.Sprintf("%s-29", newMonthStr),
-								fmt.Sprintf("%s-30", newMonthStr),
-								fmt.Sprintf("%s-31", newMonthStr))
-						case 4, 6, 9, 11:
-							res = append(res,
-								fmt.Sprintf("%s-28", newMonthStr),
-								fmt.Sprintf("%s-29", newMonthStr),
-								fmt.Sprintf("%s-30", newMonthStr))
-						case 2:
-							res = append(res,
-								fmt.Sprintf("%s-28", newMonthStr))
-							if LastDayOfMonthTime(ts).Day() == 29 {
-								res = append(res,
-									fmt.Sprintf("%s-29", newMonthStr))
-							}
-						}
-					} else {
-						res = append(res,
-							fmt.Sprintf("%s-28", newMonthStr))
-					}
-				} else {
-					res = append(res,
-						fmt.Sprintf("%s-28", newMonthStr))
-				}
-			}
+		for j := range days {
+			res = append(res, TimeToLayoutDay(days[j]))
 		}
 	}
 
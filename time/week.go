@@ -0,0 +1,140 @@
+/*
+ * Copyright (c) 2022 The Mof Authors
+ */
+
+package ptime
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Week ISO 8601 week, weeks start on Monday and week 1 is the week with
+// the first Thursday of year, so 2024-12-30 belongs to 2025-W01
+type Week struct {
+	Year int
+	Week int
+}
+
+// ISOWeekOf returns ISO 8601 week which contains ts
+func ISOWeekOf(ts time.Time) Week {
+	year, week := ts.ISOWeek()
+	return Week{Year: year, Week: week}
+}
+
+// ParseISOWeek parse ISO 8601 week like 2024-W05 or 2024W05
+func ParseISOWeek(str string) (Week, error) {
+	invalid := errors.New(fmt.Sprintf("invalid ISO week: %s, should be format of YYYY-Www", str))
+
+	str = strings.ToUpper(strings.TrimSpace(str))
+	i := strings.Index(str, "W")
+	if i != 4 && i != 5 {
+		return Week{}, invalid
+	}
+
+	yearStr := strings.TrimSuffix(str[:i], "-")
+	weekStr := str[i+1:]
+	if len(yearStr) != 4 || len(weekStr) != 2 || !isDigits(yearStr) || !isDigits(weekStr) {
+		return Week{}, invalid
+	}
+
+	year, _ := strconv.Atoi(yearStr)
+	week, _ := strconv.Atoi(weekStr)
+
+	if week < 1 || week > WeeksInYear(year) {
+		return Week{}, errors.New(fmt.Sprintf("invalid ISO week: %s, year %d has %d weeks",
+			str, year, WeeksInYear(year)))
+	}
+
+	return Week{Year: year, Week: week}, nil
+}
+
+// IsISOWeekLayout checks whether incoming string is in format of YYYY-Www
+func IsISOWeekLayout(str string) bool {
+	_, err := ParseISOWeek(str)
+	return err == nil
+}
+
+// WeeksInYear returns 52 or 53, number of ISO weeks in year
+func WeeksInYear(year int) int {
+	// December 28th is always in the last week of year
+	_, week := time.Date(year, time.December, 28, 0, 0, 0, 0, time.UTC).ISOWeek()
+	return week
+}
+
+// String returns YYYY-Www
+func (w Week) String() string {
+	return fmt.Sprintf("%04d-W%02d", w.Year, w.Week)
+}
+
+// Start returns Monday of week in UTC
+func (w Week) Start() time.Time {
+	// January 4th is always in week 1
+	jan4 := time.Date(w.Year, time.January, 4, 0, 0, 0, 0, time.UTC)
+	return WeekStart(jan4, time.Monday).AddDate(0, 0, (w.Week-1)*7)
+}
+
+// Next returns next week
+func (w Week) Next() Week {
+	return ISOWeekOf(w.Start().AddDate(0, 0, 7))
+}
+
+// ToTimePeriod returns Monday to Sunday of week
+func (w Week) ToTimePeriod() *TimePeriod {
+	start := w.Start()
+
+	return &TimePeriod{
+		Start: TimeToLayoutDay(start),
+		End:   TimeToLayoutDay(start.AddDate(0, 0, 6)),
+	}
+}
+
+// WeekStart returns first day of week which contains ts, weeks start on weekStart
+func WeekStart(ts time.Time, weekStart time.Weekday) time.Time {
+	offset := (int(ts.Weekday()) - int(weekStart) + 7) % 7
+	return truncateToDay(ts).AddDate(0, 0, -offset)
+}
+
+// WeekOfMonth returns week number of ts in its month, starts from 1
+//
+// Week 1 is the week contains the first day of month, weeks start on weekStart.
+func WeekOfMonth(ts time.Time, weekStart time.Weekday) int {
+	first := time.Date(ts.Year(), ts.Month(), 1, 0, 0, 0, 0, time.UTC)
+	offset := (int(first.Weekday()) - int(weekStart) + 7) % 7
+
+	return (ts.Day()+offset-1)/7 + 1
+}
+
+// ToWeekList returns ISO weeks overlapping time period, like 2024-W05
+func (t *TimePeriod) ToWeekList() []string {
+	res := make([]string, 0)
+
+	sp, err := t.toSpan()
+	if err != nil {
+		return res
+	}
+
+	for ts := WeekStart(sp.start, time.Monday); ts.Before(sp.end); ts = ts.AddDate(0, 0, 7) {
+		res = append(res, ISOWeekOf(ts).String())
+	}
+
+	return res
+}
+
+// ToWeekPeriods returns full weeks overlapping time period, weeks start on weekStart
+func (t *TimePeriod) ToWeekPeriods(weekStart time.Weekday) ([]*TimePeriod, error) {
+	sp, err := t.toSpan()
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*TimePeriod, 0)
+	for ts := WeekStart(sp.start, weekStart); ts.Before(sp.end); ts = ts.AddDate(0, 0, 7) {
+		res = append(res, span{start: ts, end: ts.AddDate(0, 0, 7)}.toTimePeriod())
+	}
+
+	return res, nil
+}
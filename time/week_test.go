@@ -0,0 +1,69 @@
+package ptime
+
+import (
+	"testing"
+	"time"
+)
+
+func TestISOWeek(t *testing.T) {
+	cases := map[string]string{
+		"2024-12-30": "2025-W01",
+		"2021-01-03": "2020-W53",
+		"2024-01-29": "2024-W05",
+	}
+
+	for day, expected := range cases {
+		ts, _ := StringToTime(day)
+		if got := ISOWeekOf(ts).String(); got != expected {
+			t.Errorf("%s: got %q, wanted %q", day, got, expected)
+		}
+	}
+
+	w, err := ParseISOWeek("2020W53")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if w.ToTimePeriod().String() != "2020-12-28->2021-01-03" {
+		t.Errorf("got %q, wanted %q", w.ToTimePeriod().String(), "2020-12-28->2021-01-03")
+	}
+
+	if w.Next().String() != "2021-W01" {
+		t.Errorf("got %q, wanted %q", w.Next().String(), "2021-W01")
+	}
+
+	if _, err := ParseISOWeek("2021-W53"); err == nil {
+		t.Errorf("2021 has only 52 weeks, expected error")
+	}
+}
+
+func TestWeekOfMonthAndWeekList(t *testing.T) {
+	// 2024-09-01 is Sunday
+	ts := time.Date(2024, 9, 2, 0, 0, 0, 0, time.UTC)
+
+	if got := WeekOfMonth(ts, time.Monday); got != 2 {
+		t.Errorf("got %d, wanted %d", got, 2)
+	}
+
+	if got := WeekOfMonth(ts, time.Sunday); got != 1 {
+		t.Errorf("got %d, wanted %d", got, 1)
+	}
+
+	tp := &TimePeriod{Start: "2024-12-25", End: "2025-01-06"}
+	weeks := tp.ToWeekList()
+	expected := []string{"2024-W52", "2025-W01", "2025-W02"}
+	if len(weeks) != len(expected) {
+		t.Fatalf("got %v, wanted %v", weeks, expected)
+	}
+
+	for i := range expected {
+		if weeks[i] != expected[i] {
+			t.Errorf("got %q, wanted %q", weeks[i], expected[i])
+		}
+	}
+
+	periods, _ := tp.ToWeekPeriods(time.Sunday)
+	if periods[0].String() != "2024-12-22->2024-12-28" || len(periods) != 3 {
+		t.Errorf("got %v, wanted weeks starting on Sunday", periods)
+	}
+}